@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -17,12 +18,16 @@ type ImdbClient struct {
 	httpClient  *http.Client
 	basicsFile  string
 	ratingsFile string
+	akasFile    string
+	outputDir   string
 }
 
 type ImdbConfig struct {
 	BaseURL     string
 	BasicsFile  string
 	RatingsFile string
+	AkasFile    string // Optional, skipped when empty
+	OutputDir   string // Optional, defaults to the working directory
 	Timeout     time.Duration
 }
 
@@ -36,6 +41,8 @@ func NewImdbClient(cfg *ImdbConfig) (*ImdbClient, error) {
 		baseURL:     cfg.BaseURL,
 		basicsFile:  cfg.BasicsFile,
 		ratingsFile: cfg.RatingsFile,
+		akasFile:    cfg.AkasFile,
+		outputDir:   cfg.OutputDir,
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
@@ -45,22 +52,28 @@ func NewImdbClient(cfg *ImdbConfig) (*ImdbClient, error) {
 func (c *ImdbClient) DownloadAndExtract() error {
 	log.Println("Downloading datasets from", c.baseURL)
 
-	for _, downloadPath := range []string{c.basicsFile, c.ratingsFile} {
+	downloadPaths := []string{c.basicsFile, c.ratingsFile}
+	if c.akasFile != "" {
+		downloadPaths = append(downloadPaths, c.akasFile)
+	}
+
+	for _, downloadPath := range downloadPaths {
 		url := c.baseURL + "/" + downloadPath
+		localPath := filepath.Join(c.outputDir, downloadPath)
 		log.Println("Downloading", downloadPath)
 
-		if err := c.downloadFile(url, downloadPath); err != nil {
+		if err := c.downloadFile(url, localPath); err != nil {
 			return fmt.Errorf("failed to download %s: %w", downloadPath, err)
 		}
 
 		log.Println("Extracting", downloadPath)
-		extractWithoutGzPath := downloadPath[:len(downloadPath)-3]
+		extractWithoutGzPath := localPath[:len(localPath)-3]
 
-		if err := extractGzip(downloadPath, extractWithoutGzPath); err != nil {
+		if err := extractGzip(localPath, extractWithoutGzPath); err != nil {
 			return fmt.Errorf("failed to extract %s: %w", downloadPath, err)
 		}
 
-		if err := os.Remove(downloadPath); err != nil {
+		if err := os.Remove(localPath); err != nil {
 			return fmt.Errorf("failed to remove %s: %w", downloadPath, err)
 		}
 	}
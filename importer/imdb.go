@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apkatsikas/imdb-enhanced-search/store"
+)
+
+const (
+	imdbSource     = "imdb"
+	imdbDateLayout = "2006-01-02"
+	byteOrderMark  = "\ufeff"
+)
+
+// ReadImdbRatings parses the CSV produced by IMDB's "Your Ratings" export. Every
+// rated title is recorded as watched along with the rating and date.
+func ReadImdbRatings(r io.Reader) ([]store.Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	colIndex, err := readHeader(reader, "Const", "Your Rating", "Date Rated")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []store.Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Omitting record due to error on readImdbRatings:", err)
+			continue
+		}
+
+		id := field(record, colIndex, "Const")
+		if !strings.HasPrefix(id, "tt") {
+			log.Println("Omitting record without an IMDB title id:", id)
+			continue
+		}
+
+		rating, err := strconv.Atoi(field(record, colIndex, "Your Rating"))
+		if err != nil {
+			log.Printf("Omitting %s due to invalid rating: %v", id, err)
+			continue
+		}
+
+		entry := store.Entry{
+			Id:     id,
+			Title:  field(record, colIndex, "Title"),
+			Status: store.Watched,
+			Rating: rating,
+			Source: imdbSource,
+		}
+		if rated, err := time.Parse(imdbDateLayout, field(record, colIndex, "Date Rated")); err == nil {
+			entry.Rated = rated
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// readHeader maps column names to indexes, ignoring a leading byte order mark
+func readHeader(reader *csv.Reader, requiredCols ...string) (map[string]int, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	colIndex := make(map[string]int)
+	for i, col := range header {
+		colIndex[strings.TrimSpace(strings.TrimPrefix(col, byteOrderMark))] = i
+	}
+
+	for _, col := range requiredCols {
+		if _, exists := colIndex[col]; !exists {
+			return nil, fmt.Errorf("required column '%s' not found in file", col)
+		}
+	}
+	return colIndex, nil
+}
+
+func field(record []string, colIndex map[string]int, col string) string {
+	i, exists := colIndex[col]
+	if !exists || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
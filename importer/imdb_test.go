@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/imdb-enhanced-search/store"
+)
+
+const imdbExport = byteOrderMark + `Const,Your Rating,Date Rated,Title,Original Title,URL,Title Type,IMDb Rating,Runtime (mins),Year,Genres,Num Votes,Release Date,Directors
+tt0078748,9,2023-05-14,Alien,Alien,https://www.imdb.com/title/tt0078748,Movie,8.5,117,1979,"Horror, Sci-Fi",950000,1979-05-25,Ridley Scott
+tt0090605,7,2021-11-02,Aliens,Aliens,https://www.imdb.com/title/tt0090605,Movie,8.4,137,1986,"Action, Adventure, Sci-Fi",760000,1986-07-18,James Cameron
+nm0000244,8,2021-11-02,Sigourney Weaver,,,,,,,,,,
+tt0103644,not rated,2020-01-01,Alien 3,Alien³,https://www.imdb.com/title/tt0103644,Movie,6.4,114,1992,"Action, Horror, Sci-Fi",320000,1992-05-22,David Fincher
+`
+
+func TestReadImdbRatings(t *testing.T) {
+	entries, err := ReadImdbRatings(strings.NewReader(imdbExport))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	alien := entries[0]
+	if alien.Id != "tt0078748" || alien.Rating != 9 || alien.Status != store.Watched || alien.Source != imdbSource {
+		t.Errorf("Unexpected entry for Alien: %+v", alien)
+	}
+	if !alien.Rated.Equal(time.Date(2023, 5, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected Alien to be rated on 2023-05-14, got %v", alien.Rated)
+	}
+}
+
+func TestReadImdbRatings_MissingColumn(t *testing.T) {
+	if _, err := ReadImdbRatings(strings.NewReader("Const,Title\ntt0078748,Alien\n")); err == nil {
+		t.Error("Expected error for export without a Your Rating column")
+	}
+}
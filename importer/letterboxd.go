@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/apkatsikas/imdb-enhanced-search/search"
+	"github.com/apkatsikas/imdb-enhanced-search/store"
+)
+
+const (
+	letterboxdSource     = "letterboxd"
+	letterboxdDateLayout = "2006-01-02"
+	letterboxdWatchlist  = "watchlist.csv"
+)
+
+// letterboxdFiles are the CSVs in a Letterboxd export which this importer reads
+var letterboxdFiles = []string{"diary.csv", "ratings.csv", "watched.csv", letterboxdWatchlist}
+
+type LetterboxdRow struct {
+	File      string
+	Line      int
+	Name      string
+	Year      int
+	Rating    int // Out of 10, zero when unrated
+	Date      time.Time
+	Watchlist bool
+}
+
+type LetterboxdMatch struct {
+	Row   LetterboxdRow
+	Movie search.Movie
+}
+
+// LetterboxdImport holds the entries matched to IMDB titles, along with the rows
+// which only matched approximately or not at all so they can be reviewed
+type LetterboxdImport struct {
+	Entries   []store.Entry
+	Fuzzy     []LetterboxdMatch
+	Unmatched []LetterboxdRow
+}
+
+// LetterboxdPaths expands a Letterboxd export directory into the CSVs it
+// contains, other paths are returned as they are
+func LetterboxdPaths(paths []string) []string {
+	var expanded []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, path)
+			continue
+		}
+
+		for _, name := range letterboxdFiles {
+			if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+				expanded = append(expanded, filepath.Join(path, name))
+			}
+		}
+	}
+	return expanded
+}
+
+// ReadLetterboxd reads Letterboxd diary, ratings, watched and watchlist CSVs and
+// matches every row to an IMDB title by name and year. Films in watchlist.csv
+// become watchlist entries, everything else is recorded as watched.
+func ReadLetterboxd(paths []string, matcher *search.TitleMatcher) (*LetterboxdImport, error) {
+	result := &LetterboxdImport{}
+	entries := make(map[string]store.Entry)
+	var order []string
+
+	for _, path := range paths {
+		rows, err := readLetterboxdFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		for _, row := range rows {
+			movie, kind := matcher.Match(row.Name, row.Year)
+			switch kind {
+			case search.NoMatch:
+				result.Unmatched = append(result.Unmatched, row)
+				continue
+			case search.FuzzyMatch:
+				result.Fuzzy = append(result.Fuzzy, LetterboxdMatch{Row: row, Movie: movie})
+			}
+
+			existing, exists := entries[movie.Id]
+			if !exists {
+				order = append(order, movie.Id)
+			}
+			entries[movie.Id] = mergeLetterboxdEntry(existing, exists, movie, row)
+		}
+	}
+
+	for _, id := range order {
+		result.Entries = append(result.Entries, entries[id])
+	}
+	return result, nil
+}
+
+// mergeLetterboxdEntry combines rows for the same film, watched beats watchlist
+// and the latest rating wins
+func mergeLetterboxdEntry(existing store.Entry, exists bool, movie search.Movie, row LetterboxdRow) store.Entry {
+	entry := existing
+	if !exists {
+		entry = store.Entry{Id: movie.Id, Title: movie.PrimaryTitle, Status: store.Watchlist, Source: letterboxdSource}
+	}
+
+	if !row.Watchlist {
+		entry.Status = store.Watched
+	}
+	if row.Rating > 0 && !row.Date.Before(entry.Rated) {
+		entry.Rating = row.Rating
+		entry.Rated = row.Date
+	}
+	return entry
+}
+
+func readLetterboxdFile(path string) ([]LetterboxdRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	colIndex, err := readHeader(reader, "Name", "Year")
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(path)
+	var rows []LetterboxdRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Omitting record due to error on readLetterboxd:", err)
+			continue
+		}
+
+		row := LetterboxdRow{
+			File:      name,
+			Line:      line,
+			Name:      field(record, colIndex, "Name"),
+			Watchlist: name == letterboxdWatchlist,
+		}
+		row.Year, _ = strconv.Atoi(field(record, colIndex, "Year"))
+
+		if stars, err := strconv.ParseFloat(field(record, colIndex, "Rating"), 64); err == nil {
+			row.Rating = int(math.Round(stars * 2))
+		}
+
+		date := field(record, colIndex, "Watched Date")
+		if date == "" {
+			date = field(record, colIndex, "Date")
+		}
+		row.Date, _ = time.Parse(letterboxdDateLayout, date)
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
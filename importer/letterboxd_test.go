@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apkatsikas/imdb-enhanced-search/search"
+	"github.com/apkatsikas/imdb-enhanced-search/store"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestReadLetterboxd(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"diary.csv": "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n" +
+			"2024-01-02,Alien,1979,https://boxd.it/a,4.5,,,2024-01-01\n" +
+			"2024-02-02,Alien,1979,https://boxd.it/a,5,Yes,,2024-02-01\n",
+		"ratings.csv": "Date,Name,Year,Letterboxd URI,Rating\n" +
+			"2023-06-01,Aliens,1986,https://boxd.it/b,3.5\n",
+		"watchlist.csv": "Date,Name,Year,Letterboxd URI\n" +
+			"2024-03-01,Alien,1979,https://boxd.it/a\n" +
+			"2024-03-01,Alien 3,1992,https://boxd.it/c\n" +
+			"2024-03-01,Some Festival Short,2023,https://boxd.it/d\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := &search.Dataset{
+		Movies: map[string]search.Movie{
+			"tt0078748": {Id: "tt0078748", PrimaryTitle: "Alien", StartYear: intPtr(1979)},
+			"tt0090605": {Id: "tt0090605", PrimaryTitle: "Aliens", StartYear: intPtr(1986)},
+			"tt0103644": {Id: "tt0103644", PrimaryTitle: "Alien³", StartYear: intPtr(1992)},
+		},
+		Ratings: map[string]search.Rating{},
+	}
+
+	paths := LetterboxdPaths([]string{dir})
+	if len(paths) != 3 {
+		t.Fatalf("Expected 3 files in export directory, got %v", paths)
+	}
+
+	result, err := ReadLetterboxd(paths, search.NewTitleMatcher(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries := make(map[string]store.Entry)
+	for _, entry := range result.Entries {
+		entries[entry.Id] = entry
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+
+	if alien := entries["tt0078748"]; alien.Status != store.Watched || alien.Rating != 10 {
+		t.Errorf("Expected Alien to be watched and rated 10, got %+v", alien)
+	}
+	if aliens := entries["tt0090605"]; aliens.Status != store.Watched || aliens.Rating != 7 {
+		t.Errorf("Expected Aliens to be watched and rated 7, got %+v", aliens)
+	}
+	if alien3 := entries["tt0103644"]; alien3.Status != store.Watchlist {
+		t.Errorf("Expected Alien 3 on the watchlist, got %+v", alien3)
+	}
+
+	if len(result.Unmatched) != 1 || result.Unmatched[0].Name != "Some Festival Short" || result.Unmatched[0].Line != 4 {
+		t.Errorf("Expected the festival short to be unmatched on line 4, got %+v", result.Unmatched)
+	}
+}
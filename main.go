@@ -1,78 +1,809 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/apkatsikas/imdb-enhanced-search/client"
+	"github.com/apkatsikas/imdb-enhanced-search/importer"
 	"github.com/apkatsikas/imdb-enhanced-search/search"
+	"github.com/apkatsikas/imdb-enhanced-search/server"
+	"github.com/apkatsikas/imdb-enhanced-search/store"
 )
 
 var (
 	basicsFile      = "title.basics.tsv.gz"
 	ratingsFile     = "title.ratings.tsv.gz"
+	akasFile        = ""
 	imdbDataBaseUrl = "https://datasets.imdbws.com"
 	imdbTitleUrl    = "https://www.imdb.com/title"
+	historyFile     = ""
+	presetsFile     = ""
+	profileName     = ""
 )
 
 const (
 	basicsFileEnv      = "IMDB_BASICS_FILE"
 	ratingsFileEnv     = "IMDB_RATINGS_FILE"
+	akasFileEnv        = "IMDB_AKAS_FILE"
 	imdbDataBaseUrlEnv = "IMDB_DATA_BASE_URL"
 	imdbTitleUrlEnv    = "IMDB_TITLE_URL"
+	historyFileEnv     = "IMDB_HISTORY_FILE"
+	presetsFileEnv     = "IMDB_PRESETS_FILE"
 )
 
+var (
+	queryFlag      = flag.String("query", "", "search with a query expression instead of the prompts, e.g. 'year >= 1990 and genre:horror'")
+	summaryFlag    = flag.Bool("summary", false, "show a summary of the results before browsing")
+	watchlistFlag  = flag.Bool("watchlist", false, "only search titles on your watchlist")
+	printFlag      = flag.Bool("print", false, "print links instead of opening a browser, the default when no browser can be found")
+	topFlag        = flag.Int("top", 0, "open this many of the top results at once before browsing, asking first above 10")
+	presetFlag     = flag.String("preset", "", "search with a saved preset instead of the prompts, --query replaces its query")
+	savePresetFlag = flag.String("save-preset", "", "save the search settings under this name for --preset and group")
+	profileFlag    = flag.String("profile", "", "profile whose history, watchlist and presets to use, asked for at the prompts when there are several")
+	openerFlag     = flag.String("opener", "", "command to open links with instead of the system browser, {url} is replaced by the link, e.g. 'firefox --new-tab {url}'")
+	openFlag       = flag.String("open", "imdb", "site to open titles on: imdb, parental, letterboxd, tmdb, justwatch or a custom --destination")
+
+	customDestinations []search.Destination
+)
+
+func init() {
+	flag.Func("destination", "add a site to open titles on as name=template, using {id}, {title} and {year}, e.g. 'trakt=https://trakt.tv/search/imdb/{id}' (repeatable)", func(s string) error {
+		destination, err := search.ParseDestination(s)
+		if err != nil {
+			return err
+		}
+		customDestinations = append(customDestinations, destination)
+		return nil
+	})
+}
+
 func main() {
 	log.Println("IMDB Enhanced Search")
 	log.Println("====================")
 
-	config := search.GetConfigFromUser()
+	flag.Parse()
+	loadEnv()
+
+	command := flag.Arg(0)
+	if command != "profile" {
+		selectProfile(command == "tui" || (command == "" && *queryFlag == "" && *presetFlag == ""))
+	}
+
+	switch command {
+	case "":
+		runSearch()
+	case "tui":
+		runTUI()
+	case "find":
+		runFind(flag.Args()[1:])
+	case "explain":
+		runExplain(flag.Args()[1:])
+	case "stats":
+		runStats(flag.Args()[1:])
+	case "import":
+		runImport(flag.Args()[1:])
+	case "compare":
+		runCompare()
+	case "watchlist":
+		runWatchlist(flag.Args()[1:])
+	case "serve":
+		runServe(flag.Args()[1:])
+	case "preset":
+		runPreset(flag.Args()[1:])
+	case "group":
+		runGroup(flag.Args()[1:])
+	case "profile":
+		runProfile(flag.Args()[1:])
+	default:
+		log.Fatalf("Unknown command: %s", command)
+	}
+}
+
+func runSearch() {
+	config, err := search.GetConfig(*queryFlag, presetSettings())
+	if err != nil {
+		log.Fatalf("Invalid search settings: %v", err)
+	}
+	if config.DownloadData {
+		downloadData()
+	}
+
+	history := openHistory()
+	config.SetHistory(history)
+	config.SetWatchlist(history)
+	if *watchlistFlag {
+		config.WatchlistOnly = true
+	}
+	if err := config.SetDestinations(destinations(), *openFlag); err != nil {
+		log.Fatalf("Invalid destination: %v", err)
+	}
+	config.PrintOnly = *printFlag
+	config.SetOpener(opener())
+	if *topFlag < 0 {
+		log.Fatalf("Invalid --top: %d", *topFlag)
+	}
+	config.OpenTop = *topFlag
+	if *savePresetFlag != "" {
+		savePreset(*savePresetFlag, config.Values())
+	}
+	data := loadData()
+
+	var results []search.Movie
+	if config.ShowSummary || *summaryFlag {
+		var summary *search.Summary
+		results, summary = search.FilterMoviesWithSummary(data.Movies, data.Ratings, config)
+		summary.Print(os.Stdout)
+	} else {
+		results = search.FilterMovies(data.Movies, data.Ratings, config)
+	}
+
+	log.Printf("Found %d movies matching your criteria\n", len(results))
+
+	search.OpenMoviesInBrowser(os.Stdin, os.Stdout, imdbTitleUrl, results, data.Ratings, config)
+}
+
+func runTUI() {
+	config, err := search.ConfigFromSettings(presetSettings())
+	if err != nil {
+		log.Fatalf("Invalid preset: %v", err)
+	}
+	if *queryFlag != "" {
+		if err := config.Set("query", *queryFlag); err != nil {
+			log.Fatalf("Invalid query: %v", err)
+		}
+	}
+	config.WatchlistOnly = *watchlistFlag
+
+	history := openHistory()
+	config.SetHistory(history)
+	config.SetWatchlist(history)
+	if err := config.SetDestinations(destinations(), *openFlag); err != nil {
+		log.Fatalf("Invalid destination: %v", err)
+	}
+	config.PrintOnly = *printFlag
+	config.SetOpener(opener())
+	data := loadData()
+
+	if err := search.RunTUI(imdbTitleUrl, data, config); err != nil {
+		log.Fatalf("Error running terminal UI: %v", err)
+	}
+}
+
+// opener is the command given with --opener, or nil for the system browser
+func opener() search.Opener {
+	if *openerFlag == "" {
+		return nil
+	}
+	opener, err := search.ParseOpenerCommand(*openerFlag)
+	if err != nil {
+		log.Fatalf("Invalid opener: %v", err)
+	}
+	return opener
+}
+
+// destinations are the built in sites followed by any given with --destination
+func destinations() []search.Destination {
+	return append(search.BuiltinDestinations(imdbTitleUrl), customDestinations...)
+}
+
+func runFind(args []string) {
+	data := loadData()
+
+	log.Println("Building title index...")
+	index := search.NewTitleIndex(data.Movies)
+
+	config := search.DefaultConfig()
+	if err := config.SetDestinations(destinations(), *openFlag); err != nil {
+		log.Fatalf("Invalid destination: %v", err)
+	}
+	config.PrintOnly = *printFlag
+	config.SetOpener(opener())
+	search.FindTitle(imdbTitleUrl, index, data.Ratings, strings.Join(args, " "), config)
+}
+
+// runExplain checks one title against the default settings, or those from
+// --preset and --query, asking for them at the prompts only with --ask
+func runExplain(args []string) {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	ask := flags.Bool("ask", false, "ask for the search criteria at the prompts")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		log.Fatalln("Usage: explain [--ask] <tconst or title>")
+	}
+	args = flags.Args()
+
+	config, err := search.ConfigFromSettings(presetSettings())
+	if err != nil {
+		log.Fatalf("Invalid preset: %v", err)
+	}
+	if *queryFlag != "" {
+		if err := config.Set("query", *queryFlag); err != nil {
+			log.Fatalf("Invalid query: %v", err)
+		}
+	}
+	if *ask {
+		config = search.GetConfigFromUser()
+	}
 	if config.DownloadData {
-		if basicsEnv := os.Getenv(basicsFileEnv); basicsEnv != "" {
-			basicsFile = basicsEnv
+		downloadData()
+	}
+
+	history := openHistory()
+	config.SetHistory(history)
+	config.SetWatchlist(history)
+	if *watchlistFlag {
+		config.WatchlistOnly = true
+	}
+	data := loadData()
+
+	movie, found := data.Lookup(strings.Join(args, " "))
+	if !found {
+		log.Fatalf("No title found matching %s", strings.Join(args, " "))
+	}
+
+	search.PrintExplanation(os.Stdout, movie, data.Ratings, config)
+}
+
+func runStats(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJson := flags.Bool("json", false, "output JSON instead of text tables")
+	flags.Parse(args)
+
+	data := loadData()
+	stats := search.ComputeDatasetStats(data.Movies, data.Ratings)
+
+	if !*asJson {
+		stats.Print(os.Stdout)
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(stats); err != nil {
+		log.Fatalf("Error encoding stats: %v", err)
+	}
+}
+
+func runImport(args []string) {
+	if len(args) < 2 {
+		log.Fatalln("Usage: import imdb <ratings.csv> | import letterboxd <export directory or csv files...>")
+	}
+
+	switch source := args[0]; source {
+	case "imdb":
+		importImdb(args[1])
+	case "letterboxd":
+		importLetterboxd(args[1:])
+	default:
+		log.Fatalf("Unknown import source: %s", source)
+	}
+}
+
+func importImdb(path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Error opening import file: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := importer.ReadImdbRatings(file)
+	if err != nil {
+		log.Fatalf("Error reading import file: %v", err)
+	}
+
+	saveImported(entries)
+	runCompare()
+}
+
+func importLetterboxd(paths []string) {
+	data := loadData()
+
+	log.Println("Matching Letterboxd films to IMDB titles...")
+	result, err := importer.ReadLetterboxd(importer.LetterboxdPaths(paths), search.NewTitleMatcher(data))
+	if err != nil {
+		log.Fatalf("Error reading Letterboxd export: %v", err)
+	}
+
+	saveImported(result.Entries)
+
+	if len(result.Fuzzy) > 0 {
+		fmt.Printf("\nApproximate matches, check these are right:\n")
+		for _, match := range result.Fuzzy {
+			fmt.Printf("  %s:%d %s (%d) -> %s (%s)\n", match.Row.File, match.Row.Line, match.Row.Name,
+				match.Row.Year, match.Movie.PrimaryTitle, match.Movie.Id)
+		}
+	}
+	if len(result.Unmatched) > 0 {
+		fmt.Printf("\nCould not match %d rows:\n", len(result.Unmatched))
+		for _, row := range result.Unmatched {
+			fmt.Printf("  %s:%d %s (%d)\n", row.File, row.Line, row.Name, row.Year)
+		}
+	}
+}
+
+func saveImported(entries []store.Entry) {
+	history := openHistory()
+	if err := history.Import(entries); err != nil {
+		log.Fatalf("Error saving imported entries: %v", err)
+	}
+	log.Printf("Imported %d entries into %s", len(entries), historyFile)
+}
+
+func runCompare() {
+	personal := openHistory().Ratings()
+	if len(personal) == 0 {
+		log.Println("No personal ratings found, import some with: import imdb <ratings.csv>")
+		return
+	}
+
+	data := loadData()
+	search.CompareRatings(personal, data.Movies, data.Ratings).Print(os.Stdout)
+}
+
+func runWatchlist(args []string) {
+	if len(args) == 0 {
+		log.Fatalln("Usage: watchlist list | watchlist remove <tconst...> | watchlist export [file.csv]")
+	}
+
+	history := openHistory()
+
+	switch action := args[0]; action {
+	case "list":
+		entries := history.Entries(store.Watchlist)
+		if len(entries) == 0 {
+			log.Println("Your watchlist is empty, press 'l' while browsing results to add titles")
+			return
+		}
+		for _, entry := range entries {
+			fmt.Printf("%-11s %s  %s\n", entry.Id, entry.Updated.Format(time.DateOnly), entry.Title)
 		}
-		if ratingsEnv := os.Getenv(ratingsFileEnv); ratingsEnv != "" {
-			ratingsFile = ratingsEnv
+	case "remove":
+		if len(args) < 2 {
+			log.Fatalln("Usage: watchlist remove <tconst...>")
 		}
-		if dataEnv := os.Getenv(imdbDataBaseUrlEnv); dataEnv != "" {
-			imdbDataBaseUrl = dataEnv
+		for _, id := range args[1:] {
+			removed, err := history.Remove(id, store.Watchlist)
+			if err != nil {
+				log.Fatalf("Error saving watchlist: %v", err)
+			}
+			if removed {
+				log.Printf("Removed %s from your watchlist", id)
+			} else {
+				log.Printf("%s is not on your watchlist", id)
+			}
 		}
+	case "export":
+		out := os.Stdout
+		if len(args) > 1 {
+			file, err := os.Create(args[1])
+			if err != nil {
+				log.Fatalf("Error creating export file: %v", err)
+			}
+			defer file.Close()
+			out = file
+		}
+		if err := store.WriteCSV(out, history.Entries(store.Watchlist), imdbTitleUrl); err != nil {
+			log.Fatalf("Error exporting watchlist: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown watchlist action: %s", action)
+	}
+}
 
-		imdbClient, err := client.NewImdbClient(&client.ImdbConfig{
-			BaseURL:     imdbDataBaseUrl,
-			BasicsFile:  basicsFile,
-			RatingsFile: ratingsFile,
-		})
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on, e.g. :8080 to allow other machines on the network")
+	refresh := flags.Duration("refresh", 0, "download and swap in a fresh dataset this often, e.g. 24h, 0 never refreshes")
+	flags.Parse(args)
+
+	srv := server.New(loadData(), imdbTitleUrl)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *refresh > 0 {
+		go srv.RefreshEvery(ctx, *refresh, refreshData)
+	}
+
+	if err := srv.ListenAndServe(ctx, *addr); err != nil {
+		log.Fatalf("Error serving: %v", err)
+	}
+}
+
+func runPreset(args []string) {
+	if len(args) == 0 {
+		log.Fatalln("Usage: preset list | preset show <name> | preset delete <name...>")
+	}
+
+	presets := openPresets()
+
+	switch action := args[0]; action {
+	case "list":
+		names := presets.Names()
+		if len(names) == 0 {
+			log.Println("No presets saved, save one with --save-preset <name>")
+			return
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	case "show":
+		if len(args) != 2 {
+			log.Fatalln("Usage: preset show <name>")
+		}
+		preset, found := presets.Get(args[1])
+		if !found {
+			log.Fatalf("No preset called %s", args[1])
+		}
+		for _, name := range search.ConfigFieldNames() {
+			if value, ok := preset[name]; ok {
+				fmt.Printf("%-18s %s\n", name, value)
+			}
+		}
+	case "delete":
+		if len(args) < 2 {
+			log.Fatalln("Usage: preset delete <name...>")
+		}
+		for _, name := range args[1:] {
+			deleted, err := presets.Delete(name)
+			if err != nil {
+				log.Fatalf("Error saving presets: %v", err)
+			}
+			if deleted {
+				log.Printf("Deleted preset %s", name)
+			} else {
+				log.Printf("No preset called %s", name)
+			}
+		}
+	default:
+		log.Fatalf("Unknown preset action: %s", action)
+	}
+}
+
+// runGroup searches for titles which suit everyone whose preset is named
+func runGroup(args []string) {
+	flags := flag.NewFlagSet("group", flag.ExitOnError)
+	shown := flags.Int("show", 20, "how many of the best matches to list before browsing")
+	flags.Parse(args)
+	if flags.NArg() < 2 || *shown < 0 {
+		log.Fatalln("Usage: group [--show 20] <[profile:]preset> <[profile:]preset...>")
+	}
+
+	var participants []search.Participant
+	for _, name := range flags.Args() {
+		participants = append(participants, participant(name))
+	}
+	history := openHistory()
+
+	data := loadData()
+	result := search.GroupSearch(data.Movies, data.Ratings, participants)
+	result.Print(os.Stdout, *shown)
+
+	config := search.DefaultConfig()
+	config.SetHistory(history)
+	config.SetWatchlist(history)
+	if err := config.SetDestinations(destinations(), *openFlag); err != nil {
+		log.Fatalf("Invalid destination: %v", err)
+	}
+	config.PrintOnly = *printFlag
+	config.SetOpener(opener())
+	search.OpenMoviesInBrowser(os.Stdin, os.Stdout, imdbTitleUrl, result.Movies(), data.Ratings, config)
+}
+
+// participant reads a group member given as a preset of the current profile, or
+// as profile:preset to use another profile's preset and history. An empty preset,
+// as in profile:, searches with defaults while still leaving out what that
+// profile has seen.
+func participant(name string) search.Participant {
+	presets, history := openPresets, openHistory
+	presetName := name
+	if profile, preset, found := strings.Cut(name, ":"); found {
+		checkProfile(profile)
+		presetName = preset
+		presets = func() *store.Presets { return openPresetsAt(presetsPath(profile)) }
+		history = func() *store.Store { return openHistoryAt(historyPath(profile)) }
+	}
+
+	var settings map[string]string
+	if presetName != "" {
+		preset, found := presets().Get(presetName)
+		if !found {
+			log.Fatalf("No preset called %s, save one with --save-preset %s", name, presetName)
+		}
+		settings = preset
+	}
+
+	config, err := search.ConfigFromSettings(settings)
+	if err != nil {
+		log.Fatalf("Invalid preset %s: %v", name, err)
+	}
+	config.SetHistory(history())
+	return search.Participant{Name: name, Config: config}
+}
+
+func runProfile(args []string) {
+	if len(args) == 0 {
+		log.Fatalln("Usage: profile list | profile create <name...>")
+	}
+
+	switch action := args[0]; action {
+	case "list":
+		names, err := store.Profiles()
 		if err != nil {
-			log.Fatalf("Error getting IMDB client: %v", err)
+			log.Fatalf("Error listing profiles: %v", err)
+		}
+		for _, name := range append([]string{store.DefaultProfile}, names...) {
+			current := " "
+			if name == *profileFlag || (*profileFlag == "" && name == store.DefaultProfile) {
+				current = "*"
+			}
+			fmt.Println(current, name)
 		}
-		if err := imdbClient.DownloadAndExtract(); err != nil {
-			log.Fatalf("Error downloading and extracting IMDB data: %v", err)
+	case "create":
+		if len(args) < 2 {
+			log.Fatalln("Usage: profile create <name...>")
 		}
+		for _, name := range args[1:] {
+			if err := store.CreateProfile(name); err != nil {
+				log.Fatalf("Error creating profile: %v", err)
+			}
+			log.Printf("Created profile %s, use it with --profile %s", name, name)
+		}
+	default:
+		log.Fatalf("Unknown profile action: %s", action)
 	}
+}
 
-	log.Println("Loading IMDB data...")
-	basicsWithoutGzPath := basicsFile[:len(basicsFile)-3]
-	movies, err := search.LoadMovies(basicsWithoutGzPath)
+// selectProfile sets the profile from --profile, or when ask is set and named
+// profiles exist, asks which one to use. A profile chosen either way wins over
+// the history and presets files set in the environment.
+func selectProfile(ask bool) {
+	profileName = *profileFlag
+	if profileName == "" && ask {
+		profileName = askProfile()
+	}
+	if profileName == "" {
+		return
+	}
+
+	checkProfile(profileName)
+	if historyFile != "" || presetsFile != "" {
+		log.Printf("Using profile %s instead of the history and presets files set in the environment", profileName)
+		historyFile, presetsFile = "", ""
+	}
+}
+
+// askProfile asks which profile to use when named profiles exist, returning an
+// empty name for the default
+func askProfile() string {
+	names, err := store.Profiles()
 	if err != nil {
-		log.Fatalf("Error loading movies: %v", err)
+		log.Fatalf("Error listing profiles: %v", err)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	fmt.Printf("Choose a profile (%s), Enter for %s: ", strings.Join(append([]string{store.DefaultProfile}, names...), ", "), store.DefaultProfile)
+	return strings.TrimSpace(readLine(os.Stdin))
+}
+
+// readLine reads up to the end of the line a byte at a time, so that input typed
+// or piped ahead stays unread for the prompts which follow
+func readLine(r io.Reader) string {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			break
+		}
 	}
+	return string(line)
+}
 
-	ratingsWithoutGzPath := ratingsFile[:len(ratingsFile)-3]
-	ratings, err := search.LoadRatings(ratingsWithoutGzPath)
+// checkProfile stops unless name is the default profile or one already created,
+// so a mistyped name never starts a new empty history
+func checkProfile(name string) {
+	if name == "" || name == store.DefaultProfile {
+		return
+	}
+	names, err := store.Profiles()
 	if err != nil {
-		log.Fatalf("Error loading ratings: %v", err)
+		log.Fatalf("Error listing profiles: %v", err)
 	}
+	if !slices.Contains(names, name) {
+		log.Fatalf("No profile called %s, create one with: profile create %s", name, name)
+	}
+}
 
-	log.Printf("Loaded %d movies and %d ratings", len(movies), len(ratings))
+// presetSettings are the settings of the preset named with --preset, or nil
+func presetSettings() map[string]string {
+	if *presetFlag == "" {
+		return nil
+	}
+	preset, found := openPresets().Get(*presetFlag)
+	if !found {
+		log.Fatalf("No preset called %s", *presetFlag)
+	}
+	return preset
+}
 
-	results := search.FilterMovies(movies, ratings, config)
+func savePreset(name string, settings map[string]string) {
+	if err := openPresets().Save(name, settings); err != nil {
+		log.Fatalf("Error saving preset: %v", err)
+	}
+	log.Printf("Saved preset %s", name)
+}
 
-	log.Printf("Found %d movies matching your criteria\n", len(results))
+// openPresets opens the current profile's presets, or the file set in the environment
+func openPresets() *store.Presets {
+	if presetsFile == "" {
+		presetsFile = presetsPath(profileName)
+	}
+	return openPresetsAt(presetsFile)
+}
+
+func openPresetsAt(path string) *store.Presets {
+	presets, err := store.OpenPresets(path)
+	if err != nil {
+		log.Fatalf("Error opening presets: %v", err)
+	}
+	return presets
+}
+
+func presetsPath(profile string) string {
+	path, err := store.DefaultPresetsPath(profile)
+	if err != nil {
+		log.Fatalf("Error finding presets file: %v", err)
+	}
+	return path
+}
+
+// openHistory opens the current profile's history, or the file set in the environment
+func openHistory() *store.Store {
+	if historyFile == "" {
+		historyFile = historyPath(profileName)
+	}
+	return openHistoryAt(historyFile)
+}
+
+func openHistoryAt(path string) *store.Store {
+	history, err := store.Open(path)
+	if err != nil {
+		log.Fatalf("Error opening history: %v", err)
+	}
+	return history
+}
+
+func historyPath(profile string) string {
+	path, err := store.DefaultPath(profile)
+	if err != nil {
+		log.Fatalf("Error finding history file: %v", err)
+	}
+	return path
+}
 
+func loadEnv() {
+	if basicsEnv := os.Getenv(basicsFileEnv); basicsEnv != "" {
+		basicsFile = basicsEnv
+	}
+	if ratingsEnv := os.Getenv(ratingsFileEnv); ratingsEnv != "" {
+		ratingsFile = ratingsEnv
+	}
+	if akasEnv := os.Getenv(akasFileEnv); akasEnv != "" {
+		akasFile = akasEnv
+	}
+	if dataEnv := os.Getenv(imdbDataBaseUrlEnv); dataEnv != "" {
+		imdbDataBaseUrl = dataEnv
+	}
 	if titleEnv := os.Getenv(imdbTitleUrlEnv); titleEnv != "" {
 		imdbTitleUrl = titleEnv
 	}
+	if historyEnv := os.Getenv(historyFileEnv); historyEnv != "" {
+		historyFile = historyEnv
+	}
+	if presetsEnv := os.Getenv(presetsFileEnv); presetsEnv != "" {
+		presetsFile = presetsEnv
+	}
+}
+
+func downloadData() {
+	if err := downloadTo(""); err != nil {
+		log.Fatalf("Error downloading and extracting IMDB data: %v", err)
+	}
+}
+
+func downloadTo(dir string) error {
+	imdbClient, err := client.NewImdbClient(&client.ImdbConfig{
+		BaseURL:     imdbDataBaseUrl,
+		BasicsFile:  basicsFile,
+		RatingsFile: ratingsFile,
+		AkasFile:    akasFile,
+		OutputDir:   dir,
+	})
+	if err != nil {
+		return fmt.Errorf("getting IMDB client: %w", err)
+	}
+	return imdbClient.DownloadAndExtract()
+}
+
+// refreshData downloads and loads a fresh dataset in a temporary directory, so a
+// failed download never touches the files in use, then moves the files into
+// place for the next start
+func refreshData() (*search.Dataset, error) {
+	dir, err := os.MkdirTemp(".", "imdb-refresh-")
+	if err != nil {
+		return nil, fmt.Errorf("creating download directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := downloadTo(dir); err != nil {
+		return nil, err
+	}
+
+	basics, ratings, akas := extractedPaths(dir)
+	data, err := search.LoadDataset(basics, ratings, akas)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each rename is atomic but the set is not, so after a failure the files on
+	// disk can come from different snapshots until the next refresh or download
+	currentBasics, currentRatings, currentAkas := extractedPaths("")
+	for _, paths := range [][2]string{{basics, currentBasics}, {ratings, currentRatings}, {akas, currentAkas}} {
+		fresh, current := paths[0], paths[1]
+		if fresh == "" {
+			continue
+		}
+		if err := os.Rename(fresh, current); err != nil {
+			os.Remove(fresh)
+			log.Println("Error keeping refreshed data file, the data files on disk may now mix old and new snapshots:", err)
+			break
+		}
+	}
+
+	return data, nil
+}
+
+// extractedPaths returns where the data files are once extracted into dir, the
+// alternative titles path is empty unless configured
+func extractedPaths(dir string) (string, string, string) {
+	withoutGz := func(path string) string {
+		if path == "" {
+			return ""
+		}
+		return filepath.Join(dir, strings.TrimSuffix(path, ".gz"))
+	}
+	return withoutGz(basicsFile), withoutGz(ratingsFile), withoutGz(akasFile)
+}
+
+func loadData() *search.Dataset {
+	log.Println("Loading IMDB data...")
+	data, err := search.LoadDataset(extractedPaths(""))
+	if err != nil {
+		log.Fatalf("Error loading IMDB data: %v", err)
+	}
+
+	log.Printf("Loaded %d movies and %d ratings", len(data.Movies), len(data.Ratings))
 
-	search.OpenMoviesInBrowser(imdbTitleUrl, results)
+	return data
 }
@@ -0,0 +1,259 @@
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultBatchSize = 5
+	// confirmBatchSize is the largest batch opened without asking first
+	confirmBatchSize = 10
+	maxBatchSize     = 50
+)
+
+const browseHelp = `Keys:
+  Enter, o   open the current title in the browser (or print its link) and move to the next
+  t [count]  open the next titles not yet opened at once, 5 unless a count is given
+  d          choose which site titles open on
+  s          skip to the next title without opening
+  b          go back to the previous title
+  <number>   jump to that result
+  w          mark as watched
+  n          mark as not interested
+  l          save to your watchlist
+  q          quit`
+
+// browseSession holds the state of one run of the browse loop
+type browseSession struct {
+	scanner      *bufio.Scanner
+	out          io.Writer
+	results      []Movie
+	destinations []Destination
+	current      int
+	opener       Opener
+	printOnly    bool
+	opened       map[string]bool
+}
+
+// OpenMoviesInBrowser walks through results one details card at a time, reading
+// keys from in and writing to out, letting the user open, skip, go back, jump,
+// or record titles. It returns once the user quits or runs out of results.
+// When cfg.OpenTop is set, that many results are opened at once first.
+func OpenMoviesInBrowser(in io.Reader, out io.Writer, imdbTitleUrl string, results []Movie, ratings map[string]Rating, cfg config) {
+	weights := cfg.weights.resolve(ratings)
+	destinations, current := cfg.destinationList(imdbTitleUrl)
+	b := &browseSession{
+		scanner:      bufio.NewScanner(in),
+		out:          out,
+		results:      results,
+		destinations: destinations,
+		current:      current,
+		opener:       cfg.linkOpener(),
+		printOnly:    printLinks(out, cfg),
+		opened:       make(map[string]bool),
+	}
+
+	pos := 0
+	if cfg.OpenTop > 0 {
+		pos = b.openBatch(pos, cfg.OpenTop)
+	}
+
+	for pos < len(results) {
+		movie := results[pos]
+		rating, hasRating := ratings[movie.Id]
+		opened := ""
+		if b.opened[movie.Id] {
+			opened = " (opened)"
+		}
+		fmt.Fprintf(out, "\n[%d/%d]%s %s\n", pos+1, len(results), opened, formatDetails(movie, rating, hasRating, weights))
+		action := "open"
+		if b.printOnly {
+			action = "print the link"
+		}
+		fmt.Fprintf(out, "Enter to %s on %s, t open several, d change site, s skip, b back, number to jump%s, ? help, q quit: ",
+			action, b.destinations[b.current].Name, browseOptions(cfg))
+
+		if !b.scanner.Scan() {
+			return
+		}
+
+		input := strings.ToLower(strings.TrimSpace(b.scanner.Text()))
+		switch {
+		case input == "" || input == "o":
+			b.open(movie)
+			pos++
+		case strings.HasPrefix(input, "t"):
+			count, err := parseBatchSize(strings.TrimSpace(input[1:]))
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			pos = b.openBatch(pos, count)
+		case input == "d":
+			b.current = chooseDestination(b.scanner, out, b.destinations, b.current)
+		case input == "s":
+			pos++
+		case input == "b":
+			if pos == 0 {
+				log.Println("Already at the first result")
+				continue
+			}
+			pos--
+		case input == "q":
+			log.Println("Quitting")
+			return
+		case input == "?":
+			fmt.Fprintln(out, browseHelp)
+		case cfg.history != nil && (input == "w" || input == "n"):
+			markHistory(cfg.history, movie, input == "w")
+			pos++
+		case cfg.watchlist != nil && input == "l":
+			saveToWatchlist(cfg.watchlist, movie)
+			pos++
+		default:
+			index, err := strconv.Atoi(input)
+			if err != nil || index < 1 || index > len(results) {
+				log.Println("Invalid choice, press ? for help")
+				continue
+			}
+			pos = index - 1
+		}
+	}
+
+	log.Println("No more results")
+}
+
+// open opens movie on the current destination, printing its link instead when
+// no browser is available or opening fails
+func (b *browseSession) open(movie Movie) {
+	url := b.destinations[b.current].URL(movie)
+	b.opened[movie.Id] = true
+	if b.printOnly {
+		fmt.Fprintln(b.out, "Link:", url)
+		return
+	}
+	if err := b.opener.Open(url); err != nil {
+		log.Println("Error opening browser, printing links instead:", err)
+		fmt.Fprintln(b.out, "Link:", url)
+		b.printOnly = true
+	}
+}
+
+// openBatch opens up to count results from pos onwards which have not been
+// opened yet, asking first when that is a lot of tabs. It returns the position
+// after the last one opened, or pos if nothing was.
+func (b *browseSession) openBatch(pos, count int) int {
+	if count > maxBatchSize {
+		log.Printf("Opening at most %d titles at once", maxBatchSize)
+		count = maxBatchSize
+	}
+
+	var batch []int
+	for i := pos; i < len(b.results) && len(batch) < count; i++ {
+		if !b.opened[b.results[i].Id] {
+			batch = append(batch, i)
+		}
+	}
+	if len(batch) == 0 {
+		log.Println("Every remaining title has already been opened")
+		return pos
+	}
+
+	if !b.printOnly && len(batch) > confirmBatchSize {
+		fmt.Fprintf(b.out, "Open %d tabs? y=yes: ", len(batch))
+		if !b.scanner.Scan() {
+			return pos
+		}
+		if answer := strings.ToLower(strings.TrimSpace(b.scanner.Text())); answer != "y" && answer != "yes" {
+			return pos
+		}
+	}
+
+	for _, i := range batch {
+		b.open(b.results[i])
+	}
+	if b.printOnly {
+		log.Printf("Printed %d links", len(batch))
+	} else {
+		log.Printf("Opened %d titles", len(batch))
+	}
+	return batch[len(batch)-1] + 1
+}
+
+// parseBatchSize reads the count given to the batch key, which defaults when blank
+func parseBatchSize(input string) (int, error) {
+	if input == "" {
+		return defaultBatchSize, nil
+	}
+	count, err := strconv.Atoi(input)
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid number of titles to open: %q", input)
+	}
+	return count, nil
+}
+
+// chooseDestination lists the destinations and reads a choice, keeping current
+// if the choice is blank or invalid
+func chooseDestination(scanner *bufio.Scanner, out io.Writer, destinations []Destination, current int) int {
+	for i, destination := range destinations {
+		fmt.Fprintf(out, "%2d) %-12s %s\n", i+1, destination.Name, destination.Template)
+	}
+	fmt.Fprint(out, "Choose a site to open titles on: ")
+
+	if !scanner.Scan() {
+		return current
+	}
+	input := strings.TrimSpace(scanner.Text())
+	if input == "" {
+		return current
+	}
+	for i, destination := range destinations {
+		if strconv.Itoa(i+1) == input || strings.EqualFold(destination.Name, input) {
+			return i
+		}
+	}
+	log.Println("Invalid choice, keeping", destinations[current].Name)
+	return current
+}
+
+// browseOptions lists the extra keys the browse loop accepts for cfg
+func browseOptions(cfg config) string {
+	var options string
+	if cfg.history != nil {
+		options += ", w watched, n not interested"
+	}
+	if cfg.watchlist != nil {
+		options += ", l watch later"
+	}
+	return options
+}
+
+func markHistory(history History, movie Movie, watched bool) {
+	mark, label := history.MarkDismissed, "not interested"
+	if watched {
+		mark, label = history.MarkWatched, "watched"
+	}
+
+	if err := mark(movie.Id); err != nil {
+		log.Println("Error saving history:", err)
+		return
+	}
+	log.Printf("Marked %s as %s", movie.PrimaryTitle, label)
+}
+
+func saveToWatchlist(watchlist Watchlist, movie Movie) {
+	if err := watchlist.AddToWatchlist(movie.Id, movie.PrimaryTitle); err != nil {
+		log.Println("Error saving watchlist:", err)
+		return
+	}
+	if !watchlist.OnWatchlist(movie.Id) {
+		log.Printf("%s is already watched or dismissed, not saved to your watchlist", movie.PrimaryTitle)
+		return
+	}
+	log.Printf("Saved %s to your watchlist", movie.PrimaryTitle)
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testTitleUrl = "https://www.imdb.com/title"
+
+// recordingOpener remembers every link it is asked to open
+type recordingOpener struct {
+	urls []string
+	err  error
+}
+
+func (o *recordingOpener) Open(url string) error {
+	o.urls = append(o.urls, url)
+	return o.err
+}
+
+func browseTestResults() ([]Movie, map[string]Rating) {
+	movies, ratings := setupTestData()
+	return []Movie{movies["1"], movies["3"], movies["5"]}, ratings
+}
+
+// browse runs the browse loop over the test results with keys typed one per line
+func browse(cfg config, keys ...string) string {
+	results, ratings := browseTestResults()
+	return browseResults(cfg, results, ratings, keys...)
+}
+
+func browseResults(cfg config, results []Movie, ratings map[string]Rating, keys ...string) string {
+	var out strings.Builder
+	OpenMoviesInBrowser(strings.NewReader(strings.Join(keys, "\n")+"\n"), &out, testTitleUrl, results, ratings, cfg)
+	return out.String()
+}
+
+func TestOpenMoviesInBrowser_Navigation(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []string
+	}{
+		{"open each in turn", []string{"", "o", ""}, []string{"1", "3", "5"}},
+		{"skip", []string{"s", "", "q"}, []string{"3"}},
+		{"back", []string{"", "b", "", "q"}, []string{"1", "1"}},
+		{"jump", []string{"2", "", "1", "", "q"}, []string{"3", "1"}},
+		{"quit", []string{"q", ""}, nil},
+		{"back at the first result", []string{"b", "", "q"}, []string{"1"}},
+		{"invalid choice", []string{"x", "9", "", "q"}, []string{"1"}},
+	}
+
+	for _, tt := range tests {
+		opener := &recordingOpener{}
+		cfg := DefaultConfig()
+		cfg.SetOpener(opener)
+		browse(cfg, tt.keys...)
+
+		var expected []string
+		for _, id := range tt.expected {
+			expected = append(expected, testTitleUrl+"/"+id+"/")
+		}
+		if !slices.Equal(opener.urls, expected) {
+			t.Errorf("%s: expected %v to be opened, got %v", tt.name, expected, opener.urls)
+		}
+	}
+}
+
+func TestOpenMoviesInBrowser_ChangeDestination(t *testing.T) {
+	opener := &recordingOpener{}
+	cfg := DefaultConfig()
+	cfg.SetOpener(opener)
+	out := browse(cfg, "d", "letterboxd", "", "q")
+
+	if expected := []string{"https://letterboxd.com/imdb/1/"}; !slices.Equal(opener.urls, expected) {
+		t.Errorf("Expected %v to be opened, got %v", expected, opener.urls)
+	}
+	if !strings.Contains(out, "Enter to open on letterboxd") {
+		t.Errorf("Expected the prompt to name the new site, got:\n%s", out)
+	}
+}
+
+func TestOpenMoviesInBrowser_Records(t *testing.T) {
+	history, watchlist := fakeHistory{}, fakeWatchlist{}
+	cfg := DefaultConfig()
+	cfg.SetOpener(&recordingOpener{})
+	cfg.SetHistory(history)
+	cfg.SetWatchlist(watchlist)
+	out := browse(cfg, "w", "l", "q")
+
+	if !history["1"] || !watchlist["3"] {
+		t.Errorf("Expected 1 to be marked watched and 3 saved for later, got %v and %v", history, watchlist)
+	}
+	if !strings.Contains(out, "w watched, n not interested, l watch later") {
+		t.Errorf("Expected the prompt to offer history and watchlist keys, got:\n%s", out)
+	}
+}
+
+func TestOpenMoviesInBrowser_PrintsLinks(t *testing.T) {
+	opener := &recordingOpener{}
+	cfg := DefaultConfig()
+	cfg.SetOpener(opener)
+	cfg.PrintOnly = true
+	out := browse(cfg, "", "")
+
+	if len(opener.urls) != 0 {
+		t.Errorf("Expected nothing to be opened, got %v", opener.urls)
+	}
+	for _, id := range []string{"1", "3"} {
+		if link := "Link: " + testTitleUrl + "/" + id + "/"; !strings.Contains(out, link) {
+			t.Errorf("Expected %q in output:\n%s", link, out)
+		}
+	}
+}
+
+func TestOpenMoviesInBrowser_OpenerFailure(t *testing.T) {
+	opener := &recordingOpener{err: errors.New("no browser")}
+	cfg := DefaultConfig()
+	cfg.SetOpener(opener)
+	out := browse(cfg, "", "")
+
+	if len(opener.urls) != 1 {
+		t.Errorf("Expected links to be printed after the opener fails once, got %v opened", opener.urls)
+	}
+	if !strings.Contains(out, "Link: "+testTitleUrl+"/1/") || !strings.Contains(out, "Link: "+testTitleUrl+"/3/") {
+		t.Errorf("Expected both links to be printed, got:\n%s", out)
+	}
+}
+
+func TestOpenMoviesInBrowser_Batch(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		openTop  int
+		expected []string
+	}{
+		{"default size", []string{"t"}, 0, []string{"1", "3", "5"}},
+		{"repeated batches continue", []string{"t 1", "t1", "b", "t 1"}, 0, []string{"1", "3", "5"}},
+		{"skips titles already opened", []string{"", "b", "t", "q"}, 0, []string{"1", "3", "5"}},
+		{"open top", []string{"", "q"}, 2, []string{"1", "3", "5"}},
+		{"invalid count", []string{"t 0", "t x", "q"}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		opener := &recordingOpener{}
+		cfg := DefaultConfig()
+		cfg.SetOpener(opener)
+		cfg.OpenTop = tt.openTop
+		browse(cfg, tt.keys...)
+
+		var expected []string
+		for _, id := range tt.expected {
+			expected = append(expected, testTitleUrl+"/"+id+"/")
+		}
+		if !slices.Equal(opener.urls, expected) {
+			t.Errorf("%s: expected %v to be opened, got %v", tt.name, expected, opener.urls)
+		}
+	}
+}
+
+func TestOpenMoviesInBrowser_LargeBatch(t *testing.T) {
+	var results []Movie
+	for i := range 60 {
+		results = append(results, createTestMovie(strconv.Itoa(i), "Movie", false, 2000, 90, nil))
+	}
+
+	tests := []struct {
+		keys     []string
+		expected int
+	}{
+		{[]string{"t 11", "n", "q"}, 0},
+		{[]string{"t 11", "y", "q"}, 11},
+		{[]string{"t 100", "yes", "q"}, maxBatchSize},
+		{[]string{"t 10", "q"}, 10},
+	}
+
+	for _, tt := range tests {
+		opener := &recordingOpener{}
+		cfg := DefaultConfig()
+		cfg.SetOpener(opener)
+		out := browseResults(cfg, results, nil, tt.keys...)
+
+		if len(opener.urls) != tt.expected {
+			t.Errorf("%v: expected %d opened, got %d", tt.keys, tt.expected, len(opener.urls))
+		}
+		if asked := strings.Contains(out, "tabs? y=yes"); asked != (tt.expected != 10) {
+			t.Errorf("%v: expected confirmation only above %d tabs, asked: %t", tt.keys, confirmBatchSize, asked)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Opener opens a link, normally in a web browser
+type Opener interface {
+	Open(url string) error
+}
+
+// SystemOpener opens links with the platform's default handler
+type SystemOpener struct{}
+
+func (SystemOpener) Open(url string) error {
+	name, args, err := browserCommand(url)
+	if err != nil {
+		return err
+	}
+	return runOpener(name, args)
+}
+
+// CommandOpener runs a program to open links, with {url} in its arguments
+// replaced by the link
+type CommandOpener struct {
+	Name string
+	Args []string
+}
+
+// ParseOpenerCommand reads a command line such as 'firefox --new-tab {url}'.
+// Arguments may be quoted, and the link is appended when {url} is not used.
+func ParseOpenerCommand(command string) (CommandOpener, error) {
+	fields, err := splitCommand(command)
+	if err != nil {
+		return CommandOpener{}, err
+	}
+	if len(fields) == 0 {
+		return CommandOpener{}, errors.New("opener command is empty")
+	}
+
+	opener := CommandOpener{Name: fields[0], Args: fields[1:]}
+	if !strings.Contains(command, "{url}") {
+		opener.Args = append(opener.Args, "{url}")
+	}
+	return opener, nil
+}
+
+// Open starts the command without waiting for it, since a browser started this
+// way may keep running until it is closed
+func (o CommandOpener) Open(url string) error {
+	args := make([]string, len(o.Args))
+	for i, arg := range o.Args {
+		args[i] = strings.ReplaceAll(arg, "{url}", url)
+	}
+
+	cmd := exec.Command(o.Name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", o.Name, err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// runOpener runs one of the platform handlers, which hand the link over to the
+// browser and exit, reporting any output when they fail
+func runOpener(name string, args []string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if output := strings.TrimSpace(string(output)); output != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, output)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// splitCommand splits a command line on spaces, keeping single or double
+// quoted text together
+func splitCommand(command string) ([]string, error) {
+	var (
+		fields  []string
+		field   strings.Builder
+		inField bool
+		quote   rune
+	)
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inField = r, true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+func browserCommand(url string) (string, []string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open", []string{url}, nil
+	case "linux":
+		if isWSL() {
+			return "wslview", []string{url}, nil
+		}
+		return "xdg-open", []string{url}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+// browserUnavailable explains why no browser can be opened, such as over SSH or
+// in a container, or returns nil when one probably can
+func browserUnavailable() error {
+	cmd, _, err := browserCommand("")
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "linux" && !isWSL() && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return errors.New("no display found")
+	}
+	if _, err := exec.LookPath(cmd); err != nil {
+		return fmt.Errorf("%s not found", cmd)
+	}
+	return nil
+}
+
+// SetOpener replaces the system browser used to open links, e.g. with a
+// CommandOpener. A nil opener restores the system browser.
+func (c *config) SetOpener(opener Opener) {
+	c.opener = opener
+}
+
+// linkOpener returns the configured opener, defaulting to the system browser
+func (c config) linkOpener() Opener {
+	if c.opener == nil {
+		return SystemOpener{}
+	}
+	return c.opener
+}
+
+var errPrintOnly = errors.New("printing links was asked for")
+
+// cannotOpen explains why links should be printed rather than opened, or
+// returns nil when they can be opened. A custom opener is trusted to work
+// without a display.
+func (c config) cannotOpen() error {
+	if c.PrintOnly {
+		return errPrintOnly
+	}
+	if c.opener != nil {
+		return nil
+	}
+	return browserUnavailable()
+}
+
+// printLinks reports whether links should be printed rather than opened,
+// explaining why to out when a browser was wanted but is not available
+func printLinks(out io.Writer, cfg config) bool {
+	err := cfg.cannotOpen()
+	if err == nil {
+		return false
+	}
+	if !errors.Is(err, errPrintOnly) {
+		fmt.Fprintf(out, "Cannot open a browser (%v), printing links instead\n", err)
+	}
+	return true
+}
+
+func isWSL() bool {
+	data, err := os.ReadFile("/proc/version")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseOpenerCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		name     string
+		args     []string
+		hasError bool
+	}{
+		{command: "firefox --new-tab {url}", name: "firefox", args: []string{"--new-tab", "{url}"}},
+		{command: "chromium", name: "chromium", args: []string{"{url}"}},
+		{command: `"/Applications/My Browser.app/open" '--url={url}'`, name: "/Applications/My Browser.app/open", args: []string{"--url={url}"}},
+		{command: "  ", hasError: true},
+		{command: `firefox "{url}`, hasError: true},
+	}
+
+	for _, tt := range tests {
+		opener, err := ParseOpenerCommand(tt.command)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.command)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", tt.command, err)
+			continue
+		}
+		if opener.Name != tt.name || !slices.Equal(opener.Args, tt.args) {
+			t.Errorf("Expected %s %v for %q, got %s %v", tt.name, tt.args, tt.command, opener.Name, opener.Args)
+		}
+	}
+}
+
+func TestCommandOpener_DoesNotWait(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	start := time.Now()
+	if err := (CommandOpener{Name: "sleep", Args: []string{"5"}}).Open("https://example.com"); err != nil {
+		t.Fatalf("Unexpected error opening: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Open to return while the command runs, took %v", elapsed)
+	}
+
+	if err := (CommandOpener{Name: "no-such-browser-command"}).Open("https://example.com"); err == nil {
+		t.Error("Expected an error for a missing command")
+	}
+}
+
+func TestCannotOpen(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PrintOnly = true
+	cfg.SetOpener(&recordingOpener{})
+	if cfg.cannotOpen() == nil {
+		t.Error("Expected links to be printed when asked for, even with a custom opener")
+	}
+
+	cfg.PrintOnly = false
+	if err := cfg.cannotOpen(); err != nil {
+		t.Errorf("Expected a custom opener to be trusted, got %v", err)
+	}
+}
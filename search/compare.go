@@ -0,0 +1,130 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+const ratingDifferencesShown = 5
+
+// RatingComparison compares the user's own ratings to the IMDB averages
+type RatingComparison struct {
+	Compared       int
+	NotInDataset   int
+	YourMean       float64
+	ImdbMean       float64
+	MeanDifference float64 // Positive when the user rates higher than IMDB
+	Correlation    float64
+	HigherThanImdb []RatingDifference
+	LowerThanImdb  []RatingDifference
+}
+
+type RatingDifference struct {
+	Id         string
+	Title      string
+	YourRating int
+	ImdbRating float64
+}
+
+func (d RatingDifference) difference() float64 {
+	return float64(d.YourRating) - d.ImdbRating
+}
+
+// CompareRatings compares personal ratings keyed by IMDB id to the loaded IMDB ratings
+func CompareRatings(personal map[string]int, movies map[string]Movie, ratings map[string]Rating) *RatingComparison {
+	comparison := &RatingComparison{}
+	var differences []RatingDifference
+	var sumX, sumY, sumXX, sumYY, sumXY float64
+
+	for id, yourRating := range personal {
+		rating, hasRating := ratings[id]
+		if !hasRating {
+			comparison.NotInDataset++
+			continue
+		}
+
+		title := id
+		if movie, exists := movies[id]; exists {
+			title = movie.PrimaryTitle
+		}
+		differences = append(differences, RatingDifference{
+			Id:         id,
+			Title:      title,
+			YourRating: yourRating,
+			ImdbRating: rating.AverageRating,
+		})
+
+		x, y := float64(yourRating), rating.AverageRating
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumYY += y * y
+		sumXY += x * y
+	}
+
+	n := float64(len(differences))
+	comparison.Compared = len(differences)
+	if n == 0 {
+		return comparison
+	}
+
+	comparison.YourMean = sumX / n
+	comparison.ImdbMean = sumY / n
+	comparison.MeanDifference = comparison.YourMean - comparison.ImdbMean
+	variance := math.Sqrt(n*sumXX-sumX*sumX) * math.Sqrt(n*sumYY-sumY*sumY)
+	if variance > 0 {
+		comparison.Correlation = (n*sumXY - sumX*sumY) / variance
+	}
+
+	slices.SortFunc(differences, func(a, b RatingDifference) int {
+		if c := cmp.Compare(b.difference(), a.difference()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Id, b.Id)
+	})
+	for _, d := range differences[:min(len(differences), ratingDifferencesShown)] {
+		if d.difference() > 0 {
+			comparison.HigherThanImdb = append(comparison.HigherThanImdb, d)
+		}
+	}
+	for i := len(differences) - 1; i >= max(len(differences)-ratingDifferencesShown, 0); i-- {
+		if differences[i].difference() < 0 {
+			comparison.LowerThanImdb = append(comparison.LowerThanImdb, differences[i])
+		}
+	}
+
+	return comparison
+}
+
+func (c *RatingComparison) Print(w io.Writer) {
+	fmt.Fprintf(w, "\nCompared %d of your ratings to IMDB", c.Compared)
+	if c.NotInDataset > 0 {
+		fmt.Fprintf(w, " (%d not found in the ratings dataset)", c.NotInDataset)
+	}
+	fmt.Fprintln(w)
+	if c.Compared == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "  Your average rating: %.2f\n", c.YourMean)
+	fmt.Fprintf(w, "  IMDB average rating: %.2f\n", c.ImdbMean)
+	fmt.Fprintf(w, "  Difference:          %+.2f\n", c.MeanDifference)
+	fmt.Fprintf(w, "  Correlation:         %.3f\n", c.Correlation)
+
+	printDifferences(w, "You rated higher than IMDB:", c.HigherThanImdb)
+	printDifferences(w, "You rated lower than IMDB:", c.LowerThanImdb)
+}
+
+func printDifferences(w io.Writer, heading string, differences []RatingDifference) {
+	if len(differences) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s\n", heading)
+	for _, d := range differences {
+		fmt.Fprintf(w, "  %s (%s) - you %d, IMDB %.1f\n", d.Title, d.Id, d.YourRating, d.ImdbRating)
+	}
+}
@@ -0,0 +1,26 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareRatings(t *testing.T) {
+	movies, ratings := setupTestData()
+	personal := map[string]int{"1": 10, "3": 7, "4": 6, "6": 8, "missing": 5}
+
+	comparison := CompareRatings(personal, movies, ratings)
+
+	if comparison.Compared != 4 || comparison.NotInDataset != 1 {
+		t.Errorf("Expected 4 compared and 1 missing, got %d and %d", comparison.Compared, comparison.NotInDataset)
+	}
+	if math.Abs(comparison.YourMean-7.75) > 1e-9 || math.Abs(comparison.ImdbMean-7.875) > 1e-9 {
+		t.Errorf("Expected means 7.75 and 7.875, got %v and %v", comparison.YourMean, comparison.ImdbMean)
+	}
+	if len(comparison.HigherThanImdb) != 2 || comparison.HigherThanImdb[0].Id != "1" {
+		t.Errorf("Expected 1 then 6 rated higher than IMDB, got %+v", comparison.HigherThanImdb)
+	}
+	if len(comparison.LowerThanImdb) != 2 || comparison.LowerThanImdb[0].Id != "3" {
+		t.Errorf("Expected 3 then 4 rated lower than IMDB, got %+v", comparison.LowerThanImdb)
+	}
+}
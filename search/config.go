@@ -1,14 +1,138 @@
 package search
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type config struct {
-	DownloadData bool
-	minYear      int
-	maxYear      int
-	minRating    float64
-	minVotes     int
-	maxVotes     int
-	maxRuntime   int
-	minRuntime   int
-	genres       []string
-	excludeAdult bool
+	DownloadData  bool
+	ShowSummary   bool
+	WatchlistOnly bool // Only search titles on the watchlist, requires SetWatchlist
+	PrintOnly     bool // Print links instead of opening a browser
+	OpenTop       int  // Open this many results at once before browsing
+	minYear       int
+	maxYear       int
+	minRating     float64
+	minVotes      int
+	maxVotes      int
+	maxRuntime    int
+	minRuntime    int
+	genres        []string
+	excludeAdult  bool
+	title         *titleSearch
+	query         *Query
+
+	minWeightedRating float64
+	weights           ratingWeights
+	sortBy            sortKey
+	sampleBy          sampleWeight
+	sampleStrength    float64
+
+	missingYear    missingPolicy
+	missingRuntime missingPolicy
+	missingRating  missingPolicy
+
+	filters []Filter
+
+	history     History
+	includeSeen bool
+	watchlist   Watchlist
+
+	destinations []Destination
+	destination  string
+	opener       Opener
+}
+
+// SetHistory excludes titles the user has watched or dismissed from results, and
+// lets the browse loop record new ones
+func (c *config) SetHistory(history History) {
+	c.history = history
+}
+
+// SetWatchlist lets the browse loop save titles for later, and WatchlistOnly
+// restrict results to them
+func (c *config) SetWatchlist(watchlist Watchlist) {
+	c.watchlist = watchlist
+}
+
+// AddFilter registers a custom filter which movies must match on top of the configured criteria
+func (c *config) AddFilter(filter Filter) {
+	c.filters = append(slices.Clip(c.filters), filter)
+}
+
+// filter combines every configured criterion into a single Filter
+func (c config) filter() Filter {
+	return And(c.filterList()...)
+}
+
+func (c config) filterList() []Filter {
+	filters := []Filter{
+		adultFilter{exclude: c.excludeAdult},
+		yearFilter{min: c.minYear, max: c.maxYear, missing: c.missingYear},
+		runtimeFilter{min: c.minRuntime, max: c.maxRuntime, missing: c.missingRuntime},
+		ratingFilter{
+			minRating:         c.minRating,
+			minVotes:          c.minVotes,
+			maxVotes:          c.maxVotes,
+			minWeightedRating: c.minWeightedRating,
+			weights:           c.weights,
+			missing:           c.missingRating,
+		},
+		genreFilter{genres: c.genres},
+	}
+	if c.title != nil {
+		filters = append(filters, c.title)
+	}
+	if c.query != nil {
+		filters = append(filters, queryFilter{query: c.query, weights: c.weights})
+	}
+	if c.history != nil && !c.includeSeen {
+		filters = append(filters, historyFilter{history: c.history})
+	}
+	if c.watchlist != nil && c.WatchlistOnly {
+		filters = append(filters, watchlistFilter{watchlist: c.watchlist})
+	}
+
+	return append(filters, c.filters...)
+}
+
+// missingPolicy decides what happens to titles without a value for a filtered field
+type missingPolicy int
+
+const (
+	missingExclude missingPolicy = iota
+	missingInclude
+	missingOnly
+)
+
+func (p missingPolicy) String() string {
+	switch p {
+	case missingInclude:
+		return "include"
+	case missingOnly:
+		return "only"
+	default:
+		return "exclude"
+	}
+}
+
+func parseMissingPolicy(policy string) (missingPolicy, error) {
+	switch strings.ToLower(policy) {
+	case "", "exclude":
+		return missingExclude, nil
+	case "include":
+		return missingInclude, nil
+	case "only":
+		return missingOnly, nil
+	default:
+		return missingExclude, fmt.Errorf("unknown missing data policy: %s", policy)
+	}
+}
+
+// resolve fills in settings which depend on the loaded dataset
+func (c config) resolve(ratings map[string]Rating) config {
+	c.weights = c.weights.resolve(ratings)
+	return c
 }
@@ -0,0 +1,53 @@
+package search
+
+import "fmt"
+
+const lookupCandidates = 20
+
+// Dataset holds everything loaded from the IMDB data files
+type Dataset struct {
+	Movies  map[string]Movie
+	Ratings map[string]Rating
+}
+
+// LoadDataset loads movies and ratings, and alternative titles when akasFilename is set
+func LoadDataset(basicsFilename, ratingsFilename, akasFilename string) (*Dataset, error) {
+	movies, err := LoadMovies(basicsFilename)
+	if err != nil {
+		return nil, fmt.Errorf("loading movies: %w", err)
+	}
+
+	ratings, err := LoadRatings(ratingsFilename)
+	if err != nil {
+		return nil, fmt.Errorf("loading ratings: %w", err)
+	}
+
+	if akasFilename != "" {
+		if err := LoadAkas(akasFilename, movies); err != nil {
+			return nil, fmt.Errorf("loading alternative titles: %w", err)
+		}
+	}
+
+	return &Dataset{Movies: movies, Ratings: ratings}, nil
+}
+
+// Lookup finds a movie by IMDB id, falling back to the closest title match and
+// preferring the most voted title when several match equally well
+func (d *Dataset) Lookup(idOrTitle string) (Movie, bool) {
+	if movie, exists := d.Movies[idOrTitle]; exists {
+		return movie, true
+	}
+
+	matches := NewTitleIndex(d.Movies).Search(idOrTitle, lookupCandidates)
+	if len(matches) == 0 {
+		return Movie{}, false
+	}
+
+	best := matches[0]
+	for _, match := range matches[1:] {
+		if match.Score == best.Score && d.Ratings[match.Movie.Id].NumVotes > d.Ratings[best.Movie.Id].NumVotes {
+			best = match
+		}
+	}
+	return best.Movie, true
+}
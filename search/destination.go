@@ -0,0 +1,101 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const defaultDestination = "imdb"
+
+// Destination is a named page to open for a title. Its template may use {id}
+// for the IMDB id, {title} for the URL-escaped title and {year} for the year.
+type Destination struct {
+	Name     string
+	Template string
+}
+
+// BuiltinDestinations lists the pages known out of the box, IMDB pages being
+// built from imdbTitleUrl
+func BuiltinDestinations(imdbTitleUrl string) []Destination {
+	return []Destination{
+		{Name: "imdb", Template: imdbTitleUrl + "/{id}/"},
+		{Name: "parental", Template: imdbTitleUrl + "/{id}/parentalguide"},
+		{Name: "letterboxd", Template: "https://letterboxd.com/imdb/{id}/"},
+		{Name: "tmdb", Template: "https://www.themoviedb.org/search?query={id}"},
+		{Name: "justwatch", Template: "https://www.justwatch.com/us/search?q={title}"},
+	}
+}
+
+// ParseDestination reads a custom destination written as name=template
+func ParseDestination(s string) (Destination, error) {
+	name, template, found := strings.Cut(s, "=")
+	name, template = strings.TrimSpace(name), strings.TrimSpace(template)
+	if !found || name == "" || template == "" {
+		return Destination{}, fmt.Errorf("destination %q should look like name=https://example.com/{id}", s)
+	}
+	if !strings.Contains(template, "{id}") && !strings.Contains(template, "{title}") {
+		return Destination{}, fmt.Errorf("destination template %q uses neither {id} nor {title}", template)
+	}
+	return Destination{Name: name, Template: template}, nil
+}
+
+func (d Destination) URL(movie Movie) string {
+	year := ""
+	if movie.StartYear != nil {
+		year = strconv.Itoa(*movie.StartYear)
+	}
+
+	return strings.NewReplacer(
+		"{id}", movie.Id,
+		"{title}", url.QueryEscape(movie.PrimaryTitle),
+		"{year}", year,
+	).Replace(d.Template)
+}
+
+// SetDestinations chooses the pages the browse loop can open and which one it
+// starts with. Destinations later in the list replace earlier ones with the
+// same name, so custom templates can override the built in ones.
+func (c *config) SetDestinations(destinations []Destination, current string) error {
+	var merged []Destination
+	for _, destination := range destinations {
+		merged = slices.DeleteFunc(merged, func(d Destination) bool { return d.Name == destination.Name })
+		merged = append(merged, destination)
+	}
+
+	if current == "" {
+		current = defaultDestination
+	}
+	if !slices.ContainsFunc(merged, func(d Destination) bool { return d.Name == current }) {
+		return fmt.Errorf("unknown destination %q, expected one of %s", current, strings.Join(destinationNames(merged), ", "))
+	}
+
+	c.destinations = merged
+	c.destination = current
+	return nil
+}
+
+// destinationList returns the configured destinations and the index of the
+// current one, defaulting to the built in destinations starting with IMDB
+func (c config) destinationList(imdbTitleUrl string) ([]Destination, int) {
+	destinations := c.destinations
+	if len(destinations) == 0 {
+		destinations = BuiltinDestinations(imdbTitleUrl)
+	}
+
+	current := c.destination
+	if current == "" {
+		current = defaultDestination
+	}
+	return destinations, max(slices.IndexFunc(destinations, func(d Destination) bool { return d.Name == current }), 0)
+}
+
+func destinationNames(destinations []Destination) []string {
+	names := make([]string, len(destinations))
+	for i, destination := range destinations {
+		names[i] = destination.Name
+	}
+	return names
+}
@@ -0,0 +1,67 @@
+package search
+
+import "testing"
+
+func TestDestination_URL(t *testing.T) {
+	movie := createTestMovie("tt0078748", "Alien: Director's Cut", false, 1979, 117, nil)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"https://www.imdb.com/title/{id}/", "https://www.imdb.com/title/tt0078748/"},
+		{"https://www.justwatch.com/us/search?q={title}", "https://www.justwatch.com/us/search?q=Alien%3A+Director%27s+Cut"},
+		{"https://example.com/{title}/{year}?id={id}", "https://example.com/Alien%3A+Director%27s+Cut/1979?id=tt0078748"},
+	}
+
+	for _, tt := range tests {
+		if url := (Destination{Name: "test", Template: tt.template}).URL(movie); url != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, url)
+		}
+	}
+
+	movie.StartYear = nil
+	if url := (Destination{Template: "https://example.com/{id}/{year}"}).URL(movie); url != "https://example.com/tt0078748/" {
+		t.Errorf("Expected an unknown year to be left empty, got %s", url)
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	destination, err := ParseDestination("trakt = https://trakt.tv/search/imdb/{id}")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if destination.Name != "trakt" || destination.Template != "https://trakt.tv/search/imdb/{id}" {
+		t.Errorf("Unexpected destination: %+v", destination)
+	}
+
+	for _, invalid := range []string{"trakt", "=https://trakt.tv/{id}", "trakt=", "trakt=https://trakt.tv/"} {
+		if _, err := ParseDestination(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestSetDestinations(t *testing.T) {
+	cfg := DefaultConfig()
+	destinations, current := cfg.destinationList("https://www.imdb.com/title")
+	if len(destinations) != 5 || destinations[current].Name != "imdb" {
+		t.Errorf("Expected the built in destinations starting on IMDB, got %v at %d", destinations, current)
+	}
+
+	custom := Destination{Name: "imdb", Template: "https://m.imdb.com/title/{id}/"}
+	if err := cfg.SetDestinations(append(BuiltinDestinations("https://www.imdb.com/title"), custom), "letterboxd"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	destinations, current = cfg.destinationList("https://www.imdb.com/title")
+	if len(destinations) != 5 || destinations[current].Name != "letterboxd" {
+		t.Errorf("Expected 5 destinations starting on letterboxd, got %v at %d", destinations, current)
+	}
+	if last := destinations[len(destinations)-1]; last != custom {
+		t.Errorf("Expected the custom imdb destination to replace the built in one, got %+v", last)
+	}
+
+	if err := cfg.SetDestinations(BuiltinDestinations("https://www.imdb.com/title"), "netflix"); err == nil {
+		t.Error("Expected an error choosing an unknown destination")
+	}
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func formatDetails(movie Movie, rating Rating, hasRating bool, weights ratingWeights) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s (%s)\n", movie.PrimaryTitle, formatOptionalInt(movie.StartYear))
+	if movie.originalTitle != "" && movie.originalTitle != movie.PrimaryTitle {
+		fmt.Fprintf(&b, "  Original title: %s\n", movie.originalTitle)
+	}
+	fmt.Fprintf(&b, "  Type: %s\n", movie.titleType)
+	fmt.Fprintf(&b, "  Runtime: %s min\n", formatOptionalInt(movie.runtimeMinutes))
+	fmt.Fprintf(&b, "  Genres: %s\n", strings.Join(movie.Genres, ", "))
+	if hasRating {
+		fmt.Fprintf(&b, "  Rating: %s\n", formatRating(rating, weights))
+	} else {
+		b.WriteString("  Rating: none\n")
+	}
+	fmt.Fprintf(&b, "  IMDB id: %s", movie.Id)
+
+	return b.String()
+}
+
+// formatSummary describes a movie on a single line
+func formatSummary(movie Movie, rating Rating, hasRating bool, weights ratingWeights) string {
+	summary := fmt.Sprintf("%s (%s)", movie.PrimaryTitle, formatOptionalInt(movie.StartYear))
+	if hasRating {
+		summary += " - " + formatRating(rating, weights)
+	}
+	return summary
+}
+
+func formatRating(rating Rating, weights ratingWeights) string {
+	return fmt.Sprintf("%.1f, weighted %.2f (%d votes)",
+		rating.AverageRating, weights.weightedRating(rating), rating.NumVotes)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return "unknown"
+	}
+	return strconv.Itoa(*value)
+}
+
+// TitleDetails is the exported view of a movie and its rating, e.g. for JSON
+type TitleDetails struct {
+	Id             string   `json:"id"`
+	Title          string   `json:"title"`
+	OriginalTitle  string   `json:"originalTitle,omitzero"`
+	Type           string   `json:"type"`
+	Year           *int     `json:"year"`
+	Runtime        *int     `json:"runtime"`
+	Genres         []string `json:"genres"`
+	Adult          bool     `json:"adult"`
+	Rating         *float64 `json:"rating"`
+	Votes          int      `json:"votes"`
+	WeightedRating *float64 `json:"weightedRating"`
+	URL            string   `json:"url,omitzero"` // Left for the caller to fill in
+}
+
+// Describe returns details for movies, with weighted ratings computed using c
+func (c config) Describe(movies []Movie, ratings map[string]Rating) []TitleDetails {
+	weights := c.weights.resolve(ratings)
+
+	details := make([]TitleDetails, len(movies))
+	for i, movie := range movies {
+		details[i] = TitleDetails{
+			Id:      movie.Id,
+			Title:   movie.PrimaryTitle,
+			Type:    movie.titleType,
+			Year:    movie.StartYear,
+			Runtime: movie.runtimeMinutes,
+			Genres:  movie.Genres,
+			Adult:   movie.isAdult,
+		}
+		if movie.originalTitle != movie.PrimaryTitle {
+			details[i].OriginalTitle = movie.originalTitle
+		}
+		if rating, hasRating := ratings[movie.Id]; hasRating {
+			weighted := weights.weightedRating(rating)
+			details[i].Rating = &rating.AverageRating
+			details[i].Votes = rating.NumVotes
+			details[i].WeightedRating = &weighted
+		}
+	}
+	return details
+}
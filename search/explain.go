@@ -0,0 +1,191 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Verdict is the outcome of a single filter for a single movie
+type Verdict struct {
+	Filter string
+	Passed bool
+	Reason string
+}
+
+// explainer is implemented by filters which can describe their verdict. The
+// verdict itself always comes from Match, reason only describes the values
+// involved so explanations cannot drift from FilterMovies.
+type explainer interface {
+	name() string
+	reason(movie Movie, rating Rating, hasRating bool) string
+}
+
+// Explain reports the verdict of every configured filter for movie
+func Explain(movie Movie, ratings map[string]Rating, cfg config) []Verdict {
+	cfg = cfg.resolve(ratings)
+	rating, hasRating := ratings[movie.Id]
+
+	var verdicts []Verdict
+	custom := 0
+	for _, filter := range cfg.filterList() {
+		verdict := Verdict{Passed: filter.Match(movie, rating, hasRating)}
+
+		if e, ok := filter.(explainer); ok {
+			verdict.Filter = e.name()
+			verdict.Reason = e.reason(movie, rating, hasRating)
+		} else {
+			custom++
+			verdict.Filter = fmt.Sprintf("custom filter %d", custom)
+			verdict.Reason = "matched"
+			if !verdict.Passed {
+				verdict.Reason = "did not match"
+			}
+		}
+
+		verdicts = append(verdicts, verdict)
+	}
+
+	return verdicts
+}
+
+// PrintExplanation writes a movie's summary followed by every filter verdict
+func PrintExplanation(w io.Writer, movie Movie, ratings map[string]Rating, cfg config) {
+	rating, hasRating := ratings[movie.Id]
+	fmt.Fprintln(w, formatSummary(movie, rating, hasRating, cfg.weights.resolve(ratings)))
+
+	included := true
+	for _, verdict := range Explain(movie, ratings, cfg) {
+		status := "PASS"
+		if !verdict.Passed {
+			status = "FAIL"
+			included = false
+		}
+		fmt.Fprintf(w, "  %s %-8s %s\n", status, verdict.Filter, verdict.Reason)
+	}
+
+	if included {
+		fmt.Fprintln(w, "Included in results")
+	} else {
+		fmt.Fprintln(w, "Excluded from results")
+	}
+}
+
+func (f adultFilter) name() string { return "adult" }
+
+func (f adultFilter) reason(movie Movie, _ Rating, _ bool) string {
+	switch {
+	case !f.exclude:
+		return "adult titles allowed"
+	case movie.isAdult:
+		return "adult title, adult titles excluded"
+	default:
+		return "not an adult title"
+	}
+}
+
+func (f yearFilter) name() string { return "year" }
+
+func (f yearFilter) reason(movie Movie, _ Rating, _ bool) string {
+	return explainRange("year", movie.StartYear, f.min, f.max, f.missing)
+}
+
+func (f runtimeFilter) name() string { return "runtime" }
+
+func (f runtimeFilter) reason(movie Movie, _ Rating, _ bool) string {
+	return explainRange("runtime", movie.runtimeMinutes, f.min, f.max, f.missing)
+}
+
+func explainRange(field string, value *int, lower, upper int, missing missingPolicy) string {
+	switch {
+	case value == nil && missing == missingExclude:
+		return fmt.Sprintf("no %s found, titles without one are excluded", field)
+	case value == nil:
+		return fmt.Sprintf("no %s found, titles without one are included", field)
+	case missing == missingOnly:
+		return fmt.Sprintf("%s %d, only titles without one are wanted", field, *value)
+	case *value < lower:
+		return fmt.Sprintf("%s %d < min %d", field, *value, lower)
+	case *value > upper:
+		return fmt.Sprintf("%s %d > max %d", field, *value, upper)
+	default:
+		return fmt.Sprintf("%s %d within %s", field, *value, formatBounds(lower, upper))
+	}
+}
+
+func (f ratingFilter) name() string { return "rating" }
+
+func (f ratingFilter) reason(_ Movie, rating Rating, hasRating bool) string {
+	switch {
+	case !hasRating && f.missing == missingExclude:
+		return "no rating found, unrated titles are excluded"
+	case !hasRating:
+		return "no rating found, unrated titles are included"
+	case f.missing == missingOnly:
+		return fmt.Sprintf("rating %.1f, only unrated titles are wanted", rating.AverageRating)
+	case rating.AverageRating < f.minRating:
+		return fmt.Sprintf("rating %.1f < min %.1f", rating.AverageRating, f.minRating)
+	case rating.NumVotes < f.minVotes:
+		return fmt.Sprintf("votes %d < min %d", rating.NumVotes, f.minVotes)
+	case rating.NumVotes > f.maxVotes:
+		return fmt.Sprintf("votes %d > max %d", rating.NumVotes, f.maxVotes)
+	}
+
+	weighted := f.weights.weightedRating(rating)
+	if f.minWeightedRating != 0 && weighted < f.minWeightedRating {
+		return fmt.Sprintf("weighted rating %.2f < min %.2f", weighted, f.minWeightedRating)
+	}
+	return fmt.Sprintf("rating %.1f, weighted %.2f, %d votes within %s",
+		rating.AverageRating, weighted, rating.NumVotes, formatBounds(f.minVotes, f.maxVotes))
+}
+
+func (f genreFilter) name() string { return "genre" }
+
+func (f genreFilter) reason(movie Movie, _ Rating, _ bool) string {
+	genres := strings.Join(movie.Genres, ", ")
+	if genres == "" {
+		genres = "none"
+	}
+
+	if len(f.genres) == 0 {
+		return fmt.Sprintf("genres %s, any genre allowed", genres)
+	}
+	if hasGenre(movie.Genres, f.genres) {
+		return fmt.Sprintf("genres %s include one of %s", genres, strings.Join(f.genres, ", "))
+	}
+	return fmt.Sprintf("genres %s include none of %s", genres, strings.Join(f.genres, ", "))
+}
+
+func (ts *titleSearch) name() string { return "title" }
+
+func (ts *titleSearch) reason(movie Movie, _ Rating, _ bool) string {
+	titles := append([]string{movie.PrimaryTitle, movie.originalTitle}, movie.akas...)
+	for _, title := range titles {
+		if ts.matches(title) {
+			return fmt.Sprintf("%q matches title search %q", title, ts.query)
+		}
+	}
+	return fmt.Sprintf("no title matches title search %q", ts.query)
+}
+
+func (f queryFilter) name() string { return "query" }
+
+func (f queryFilter) reason(movie Movie, rating Rating, hasRating bool) string {
+	if f.Match(movie, rating, hasRating) {
+		return fmt.Sprintf("matches %s", f.query)
+	}
+	return fmt.Sprintf("does not match %s", f.query)
+}
+
+func formatBounds(lower, upper int) string {
+	return formatBound(lower, 0) + "-" + formatBound(upper, math.MaxInt)
+}
+
+func formatBound(bound, unbounded int) string {
+	if bound == unbounded {
+		return "any"
+	}
+	return strconv.Itoa(bound)
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExplain_Reasons(t *testing.T) {
+	movies, ratings := setupTestData()
+	delete(ratings, "8")
+
+	cfg := config{
+		excludeAdult: true,
+		minYear:      2019,
+		maxYear:      2022,
+		minRuntime:   100,
+		maxRuntime:   180,
+		minRating:    8.0,
+		minVotes:     10000,
+		maxVotes:     math.MaxInt,
+		genres:       []string{"Action"},
+	}
+
+	testCases := []struct {
+		id     string
+		filter string
+		reason string
+	}{
+		{"2", "adult", "adult title, adult titles excluded"},
+		{"6", "year", "year 2005 < min 2019"},
+		{"7", "runtime", "runtime 200 > max 180"},
+		{"4", "rating", "rating 6.5 < min 8.0"},
+		{"7", "rating", "votes 5000 < min 10000"},
+		{"8", "rating", "no rating found, unrated titles are excluded"},
+		{"6", "genre", "genres Drama include none of Action"},
+		{"1", "runtime", "runtime 120 within 100-180"},
+	}
+
+	for _, tc := range testCases {
+		found := false
+		for _, verdict := range Explain(movies[tc.id], ratings, cfg) {
+			if verdict.Filter != tc.filter {
+				continue
+			}
+			found = true
+			if verdict.Reason != tc.reason {
+				t.Errorf("Movie %s %s: expected reason %q, got %q", tc.id, tc.filter, tc.reason, verdict.Reason)
+			}
+		}
+		if !found {
+			t.Errorf("Movie %s: no verdict for filter %s", tc.id, tc.filter)
+		}
+	}
+}
+
+func TestExplain_ConsistentWithFilterMovies(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	query, err := ParseQuery("not title ~ pack")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []config{
+		{excludeAdult: true, maxVotes: math.MaxInt, minYear: 2000, maxYear: 2025, minRuntime: 0, maxRuntime: 300},
+		{excludeAdult: false, maxVotes: math.MaxInt, minYear: 2018, maxYear: 2021, minRuntime: 90, maxRuntime: 150, minRating: 7.5, minVotes: 5000, genres: []string{"Action"}},
+		{excludeAdult: true, maxVotes: math.MaxInt, minYear: 2019, maxYear: 2022, minRuntime: 100, maxRuntime: 180, minRating: 8.0, query: query},
+	}
+	testCases[2].AddFilter(FilterFunc(func(movie Movie, _ Rating, _ bool) bool {
+		return movie.Id != "1"
+	}))
+
+	for i, cfg := range testCases {
+		included := make(map[string]bool)
+		for _, movie := range FilterMoviesSync(movies, ratings, cfg) {
+			included[movie.Id] = true
+		}
+
+		for _, movie := range movies {
+			passed := true
+			for _, verdict := range Explain(movie, ratings, cfg) {
+				passed = passed && verdict.Passed
+			}
+			if passed != included[movie.Id] {
+				t.Errorf("Test case %d: explain says %v for movie %s but FilterMovies says %v", i, passed, movie.Id, included[movie.Id])
+			}
+		}
+	}
+}
+
+func TestPrintExplanation(t *testing.T) {
+	movies, ratings := setupTestData()
+	cfg := DefaultConfig()
+	cfg.maxRuntime = 110
+
+	var out bytes.Buffer
+	PrintExplanation(&out, movies["1"], ratings, cfg)
+
+	if !strings.Contains(out.String(), "FAIL runtime  runtime 120 > max 110") {
+		t.Errorf("Expected failing runtime verdict, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Excluded from results") {
+		t.Errorf("Expected movie to be reported as excluded, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,365 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// configField is a search setting which can be read and written as text, so
+// that interfaces other than the prompts can edit a config by name
+type configField struct {
+	name  string
+	label string
+	get   func(c config) string
+	set   func(c *config, value string) error
+}
+
+var configFields = []configField{
+	intField("minYear", "Minimum year", func(c *config) *int { return &c.minYear }, defaultMinYear),
+	intField("maxYear", "Maximum year", func(c *config) *int { return &c.maxYear }, defaultMaxYear),
+	intField("minRuntime", "Minimum run time", func(c *config) *int { return &c.minRuntime }, defaultMinRuntime),
+	intField("maxRuntime", "Maximum run time", func(c *config) *int { return &c.maxRuntime }, defaultMaxRuntime),
+	floatField("minRating", "Minimum rating", func(c *config) *float64 { return &c.minRating }, defaultMinRating),
+	floatField("minWeightedRating", "Minimum weighted rating", func(c *config) *float64 { return &c.minWeightedRating }, 0),
+	intField("minVotes", "Minimum votes", func(c *config) *int { return &c.minVotes }, defaultMinVotes),
+	intField("maxVotes", "Maximum votes", func(c *config) *int { return &c.maxVotes }, defaultMaxVotes),
+	{
+		name:  "genres",
+		label: "Genres",
+		get:   func(c config) string { return strings.Join(c.genres, ",") },
+		set: func(c *config, value string) error {
+			c.genres = splitList(value)
+			return nil
+		},
+	},
+	boolField("excludeAdult", "Exclude adult titles", func(c *config) *bool { return &c.excludeAdult }),
+	missingField("missingYear", "Unknown year", func(c *config) *missingPolicy { return &c.missingYear }),
+	missingField("missingRuntime", "Unknown run time", func(c *config) *missingPolicy { return &c.missingRuntime }),
+	missingField("missingRating", "No rating", func(c *config) *missingPolicy { return &c.missingRating }),
+	{
+		name:  "title",
+		label: "Title search",
+		get: func(c config) string {
+			if c.title == nil {
+				return ""
+			}
+			return c.title.source
+		},
+		set: func(c *config, value string) error {
+			if value == "" {
+				c.title = nil
+				return nil
+			}
+			mode, ignoreAccents := titleMatchSubstring, true
+			if c.title != nil {
+				mode, ignoreAccents = c.title.mode, c.title.ignoreAccents
+			}
+			title, err := newTitleSearch(value, mode.String(), ignoreAccents)
+			if err != nil {
+				return err
+			}
+			c.title = title
+			return nil
+		},
+	},
+	{
+		name:  "titleMode",
+		label: "Title match mode",
+		get: func(c config) string {
+			if c.title == nil {
+				return ""
+			}
+			return c.title.mode.String()
+		},
+		set: func(c *config, value string) error {
+			if c.title == nil {
+				if value == "" {
+					return nil
+				}
+				return fmt.Errorf("a title match mode needs a title search")
+			}
+			title, err := newTitleSearch(c.title.source, value, c.title.ignoreAccents)
+			if err != nil {
+				return err
+			}
+			c.title = title
+			return nil
+		},
+	},
+	{
+		name:  "matchAccents",
+		label: "Match accents in titles",
+		get: func(c config) string {
+			if c.title == nil || c.title.ignoreAccents {
+				return ""
+			}
+			return "yes"
+		},
+		set: func(c *config, value string) error {
+			matchAccents, err := parseYesNo(value)
+			if err != nil {
+				return err
+			}
+			if c.title == nil {
+				if !matchAccents {
+					return nil
+				}
+				return fmt.Errorf("matching accents needs a title search")
+			}
+			title, err := newTitleSearch(c.title.source, c.title.mode.String(), !matchAccents)
+			if err != nil {
+				return err
+			}
+			c.title = title
+			return nil
+		},
+	},
+	{
+		name:  "query",
+		label: "Query",
+		get: func(c config) string {
+			if c.query == nil {
+				return ""
+			}
+			return c.query.String()
+		},
+		set: func(c *config, value string) error {
+			if value == "" {
+				c.query = nil
+				return nil
+			}
+			query, err := ParseQuery(value)
+			if err != nil {
+				return err
+			}
+			c.query = query
+			return nil
+		},
+	},
+	{
+		name:  "sort",
+		label: "Sort by",
+		get:   func(c config) string { return c.sortBy.String() },
+		set: func(c *config, value string) (err error) {
+			c.sortBy, err = parseSortKey(value)
+			return err
+		},
+	},
+	{
+		name:  "sample",
+		label: "Sample bias",
+		get:   func(c config) string { return c.sampleBy.String() },
+		set: func(c *config, value string) (err error) {
+			c.sampleBy, err = parseSampleWeight(value)
+			return err
+		},
+	},
+	{
+		name:  "sampleStrength",
+		label: "Sample strength",
+		get: func(c config) string {
+			if c.sampleStrength == defaultSampleStrength {
+				return ""
+			}
+			return strconv.FormatFloat(c.sampleStrength, 'g', -1, 64)
+		},
+		set: func(c *config, value string) (err error) {
+			c.sampleStrength, err = parseSampleStrength(value)
+			return err
+		},
+	},
+	intField("weightMinVotes", "Weighting min votes", func(c *config) *int { return &c.weights.minVotes }, defaultWeightMinVotes),
+	floatField("weightPriorMean", "Weighting prior mean", func(c *config) *float64 { return &c.weights.priorMean }, 0),
+	boolField("includeSeen", "Include seen titles", func(c *config) *bool { return &c.includeSeen }),
+	boolField("watchlistOnly", "Watchlist only", func(c *config) *bool { return &c.WatchlistOnly }),
+}
+
+// ConfigFieldNames lists every setting accepted by Set, in the order they are applied
+func ConfigFieldNames() []string {
+	names := make([]string, len(configFields))
+	for i, field := range configFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// ConfigFromValues builds a config from the defaults and any settings named in
+// values, such as URL query parameters. Other keys are ignored.
+func ConfigFromValues(values url.Values) (config, error) {
+	cfg := DefaultConfig()
+	for _, field := range configFields {
+		if values.Has(field.name) {
+			if err := cfg.Set(field.name, values.Get(field.name)); err != nil {
+				return config{}, err
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// ConfigFromSettings builds a config from the defaults and settings by name, as
+// returned by Values. Unlike ConfigFromValues, unknown names are an error.
+func ConfigFromSettings(settings map[string]string) (config, error) {
+	for name := range settings {
+		if _, found := lookupField(name); !found {
+			return config{}, fmt.Errorf("unknown setting: %s", name)
+		}
+	}
+
+	values := make(url.Values, len(settings))
+	for _, field := range configFields {
+		for name, value := range settings {
+			if strings.EqualFold(name, field.name) {
+				values.Set(field.name, value)
+			}
+		}
+	}
+	return ConfigFromValues(values)
+}
+
+// Set changes the setting called name from its text form, an empty value
+// restores the default
+func (c *config) Set(name, value string) error {
+	field, found := lookupField(name)
+	if !found {
+		return fmt.Errorf("unknown setting: %s", name)
+	}
+	if err := field.set(c, strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get returns the text form of the setting called name, empty for a default
+func (c config) Get(name string) (string, error) {
+	field, found := lookupField(name)
+	if !found {
+		return "", fmt.Errorf("unknown setting: %s", name)
+	}
+	return field.get(c), nil
+}
+
+// Values returns the text form of every setting which differs from its default
+func (c config) Values() map[string]string {
+	defaults := DefaultConfig()
+	values := make(map[string]string)
+	for _, field := range configFields {
+		if value := field.get(c); value != field.get(defaults) {
+			values[field.name] = value
+		}
+	}
+	return values
+}
+
+func lookupField(name string) (configField, bool) {
+	i := slices.IndexFunc(configFields, func(f configField) bool {
+		return strings.EqualFold(f.name, name)
+	})
+	if i < 0 {
+		return configField{}, false
+	}
+	return configFields[i], true
+}
+
+func intField(name, label string, ptr func(c *config) *int, def int) configField {
+	return configField{
+		name:  name,
+		label: label,
+		get: func(c config) string {
+			if v := *ptr(&c); v != def {
+				return strconv.Itoa(v)
+			}
+			return ""
+		},
+		set: func(c *config, value string) error {
+			if value == "" {
+				*ptr(c) = def
+				return nil
+			}
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a whole number", value)
+			}
+			*ptr(c) = v
+			return nil
+		},
+	}
+}
+
+func floatField(name, label string, ptr func(c *config) *float64, def float64) configField {
+	return configField{
+		name:  name,
+		label: label,
+		get: func(c config) string {
+			if v := *ptr(&c); v != def {
+				return strconv.FormatFloat(v, 'g', -1, 64)
+			}
+			return ""
+		},
+		set: func(c *config, value string) error {
+			if value == "" {
+				*ptr(c) = def
+				return nil
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+			*ptr(c) = v
+			return nil
+		},
+	}
+}
+
+func boolField(name, label string, ptr func(c *config) *bool) configField {
+	return configField{
+		name:  name,
+		label: label,
+		get: func(c config) string {
+			if *ptr(&c) {
+				return "yes"
+			}
+			return ""
+		},
+		set: func(c *config, value string) (err error) {
+			*ptr(c), err = parseYesNo(value)
+			return err
+		},
+	}
+}
+
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "n", "no", "false", "0":
+		return false, nil
+	case "y", "yes", "true", "1":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%q is not yes or no", value)
+	}
+}
+
+func missingField(name, label string, ptr func(c *config) *missingPolicy) configField {
+	return configField{
+		name:  name,
+		label: label,
+		get:   func(c config) string { return ptr(&c).String() },
+		set: func(c *config, value string) (err error) {
+			*ptr(c), err = parseMissingPolicy(value)
+			return err
+		},
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
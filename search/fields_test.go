@@ -0,0 +1,111 @@
+package search
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestConfigSet(t *testing.T) {
+	cfg := DefaultConfig()
+	settings := map[string]string{
+		"minYear":      "1990",
+		"maxRuntime":   "120",
+		"minRating":    "7.5",
+		"genres":       "Horror, Thriller,",
+		"missingYear":  "include",
+		"title":        "night",
+		"titleMode":    "word",
+		"query":        "votes > 1000",
+		"sort":         "weighted",
+		"excludeAdult": "yes",
+	}
+	for _, name := range ConfigFieldNames() {
+		if value, ok := settings[name]; ok {
+			if err := cfg.Set(name, value); err != nil {
+				t.Fatalf("Unexpected error setting %s: %v", name, err)
+			}
+		}
+	}
+
+	if cfg.minYear != 1990 || cfg.maxRuntime != 120 || cfg.minRating != 7.5 {
+		t.Errorf("Numeric settings not applied: %+v", cfg)
+	}
+	if len(cfg.genres) != 2 || cfg.genres[1] != "Thriller" {
+		t.Errorf("Expected genres [Horror Thriller], got %v", cfg.genres)
+	}
+	if cfg.title == nil || cfg.title.mode != titleMatchWord || cfg.query == nil || !cfg.excludeAdult {
+		t.Errorf("Title, query or adult settings not applied: %+v", cfg)
+	}
+
+	expected := map[string]string{
+		"minYear":      "1990",
+		"maxRuntime":   "120",
+		"minRating":    "7.5",
+		"genres":       "Horror,Thriller",
+		"missingYear":  "include",
+		"title":        "night",
+		"titleMode":    "word",
+		"query":        "votes > 1000",
+		"sort":         "weighted",
+		"excludeAdult": "yes",
+	}
+	if values := cfg.Values(); !maps.Equal(values, expected) {
+		t.Errorf("Expected values %v, got %v", expected, values)
+	}
+
+	if err := cfg.Set("minYear", ""); err != nil || cfg.minYear != defaultMinYear {
+		t.Errorf("Expected an empty value to restore the default, got %d (%v)", cfg.minYear, err)
+	}
+}
+
+func TestConfigSet_Errors(t *testing.T) {
+	tests := []struct{ name, value string }{
+		{"minYear", "nineteen"},
+		{"minRating", "high"},
+		{"excludeAdult", "maybe"},
+		{"missingRating", "sometimes"},
+		{"query", "year >="},
+		{"titleMode", "word"},
+		{"matchAccents", "yes"},
+		{"sort", "length"},
+		{"sampleStrength", "-1"},
+		{"sampleStrength", "NaN"},
+		{"colour", "blue"},
+	}
+
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		if err := cfg.Set(tt.name, tt.value); err == nil {
+			t.Errorf("Expected an error setting %s to %q", tt.name, tt.value)
+		}
+	}
+}
+
+func TestConfigFromSettings_RoundTrips(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Set("title", "night")
+	cfg.Set("titleMode", "word")
+	cfg.Set("genres", "Horror")
+	cfg.Set("maxRuntime", "100")
+
+	restored, err := ConfigFromSettings(cfg.Values())
+	if err != nil {
+		t.Fatalf("Unexpected error restoring settings: %v", err)
+	}
+	if !maps.Equal(restored.Values(), cfg.Values()) {
+		t.Errorf("Expected %v, got %v", cfg.Values(), restored.Values())
+	}
+
+	cfg.Set("matchAccents", "yes")
+	restored, err = ConfigFromSettings(cfg.Values())
+	if err != nil {
+		t.Fatalf("Unexpected error restoring settings: %v", err)
+	}
+	if restored.title == nil || restored.title.ignoreAccents || restored.title.mode != titleMatchWord {
+		t.Errorf("Expected accents to be matched in word mode, got %+v", restored.title)
+	}
+
+	if _, err := ConfigFromSettings(map[string]string{"colour": "blue"}); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+}
@@ -1,44 +1,63 @@
 package search
 
 import (
+	"context"
 	"math/rand/v2"
 	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 )
 
 // FilterMoviesSync filters movies synchronously
-func FilterMoviesSync(movies map[string]Movie, ratings map[string]rating, config config) []Movie {
+func FilterMoviesSync(movies map[string]Movie, ratings map[string]Rating, config config) []Movie {
+	config = config.resolve(ratings)
 	movieSlice := mapToSlice(movies)
-	filtered := filterMovieSlice(movieSlice, ratings, config)
-	randomizeResults(filtered)
+	filtered := filterMovieSlice(context.Background(), movieSlice, ratings, config)
+	orderResults(filtered, ratings, config)
 	return filtered
 }
 
 // FilterMovies filters movies concurrently using worker pool
-func FilterMovies(movies map[string]Movie, ratings map[string]rating, config config) []Movie {
+func FilterMovies(movies map[string]Movie, ratings map[string]Rating, config config) []Movie {
+	results, _, _ := filterMovies(context.Background(), movies, ratings, config, false)
+	return results
+}
+
+// FilterMoviesWithSummary filters movies like FilterMovies, with each worker also
+// summarizing its share of the results
+func FilterMoviesWithSummary(movies map[string]Movie, ratings map[string]Rating, config config) ([]Movie, *Summary) {
+	results, summary, _ := filterMovies(context.Background(), movies, ratings, config, true)
+	return results, summary
+}
+
+// filterMoviesContext filters movies like FilterMovies, giving up early with the
+// context's error once it is cancelled
+func filterMoviesContext(ctx context.Context, movies map[string]Movie, ratings map[string]Rating, config config) ([]Movie, error) {
+	results, _, err := filterMovies(ctx, movies, ratings, config, false)
+	return results, err
+}
+
+func filterMovies(ctx context.Context, movies map[string]Movie, ratings map[string]Rating, config config, summarize bool) ([]Movie, *Summary, error) {
+	config = config.resolve(ratings)
 	movieSlice := mapToSlice(movies)
 
 	resultsChan := make(chan Movie, len(movieSlice))
 
 	var wg sync.WaitGroup
-	numWorkers := runtime.NumCPU()
-
-	altWorkers := os.Getenv("IMDB_SEARCH_WORKERS")
-	if altWorkers != "" {
-		if num, err := strconv.Atoi(altWorkers); err == nil {
-			numWorkers = num
-		}
-	}
-	chunks := partitionSlice(movieSlice, numWorkers)
+	chunks := partitionSlice(movieSlice, numWorkers())
+	summaryChan := make(chan *Summary, len(chunks))
 
 	for _, chunk := range chunks {
 		wg.Add(1)
 		go func(movies []Movie) {
 			defer wg.Done()
-			filtered := filterMovieSlice(movies, ratings, config)
+			filtered := filterMovieSlice(ctx, movies, ratings, config)
+			if summarize {
+				summaryChan <- summarizeSlice(filtered, ratings)
+			}
 			for _, movie := range filtered {
 				resultsChan <- movie
 			}
@@ -48,20 +67,42 @@ func FilterMovies(movies map[string]Movie, ratings map[string]rating, config con
 	go func() {
 		wg.Wait()
 		close(resultsChan)
+		close(summaryChan)
 	}()
 
 	results := collectFromChannel(resultsChan)
-	randomizeResults(results)
-	return results
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	orderResults(results, ratings, config)
+
+	if !summarize {
+		return results, nil, nil
+	}
+
+	summary := newSummary()
+	for partial := range summaryChan {
+		summary.merge(partial)
+	}
+	summary.finish()
+	return results, summary, nil
 }
 
-func filterMovieSlice(movies []Movie, ratings map[string]rating, cfg config) []Movie {
+// cancelCheckInterval is how many movies a worker filters between checks for
+// cancellation
+const cancelCheckInterval = 4096
+
+func filterMovieSlice(ctx context.Context, movies []Movie, ratings map[string]Rating, cfg config) []Movie {
 	results := make([]Movie, 0, len(movies))
+	filter := cfg.filter()
 
-	for _, movie := range movies {
+	for i, movie := range movies {
+		if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
 		rating, hasRating := ratings[movie.Id]
 
-		if shouldIncludeMovie(movie, rating, hasRating, cfg) {
+		if filter.Match(movie, rating, hasRating) {
 			results = append(results, movie)
 		}
 	}
@@ -69,51 +110,88 @@ func filterMovieSlice(movies []Movie, ratings map[string]rating, cfg config) []M
 	return results
 }
 
-func shouldIncludeMovie(movie Movie, rating rating, hasRating bool, cfg config) bool {
-	return passesAdultFilter(movie, cfg) &&
-		passesYearFilter(movie, cfg) &&
-		passesRuntimeFilter(movie, cfg) &&
-		passesRatingFilter(rating, hasRating, cfg) &&
-		passesGenreFilter(movie, cfg)
+type adultFilter struct {
+	exclude bool
+}
+
+func (f adultFilter) Match(movie Movie, _ Rating, _ bool) bool {
+	return !f.exclude || !movie.isAdult
 }
 
-func passesAdultFilter(movie Movie, cfg config) bool {
-	return !cfg.excludeAdult || !movie.isAdult
+type yearFilter struct {
+	min, max int
+	missing  missingPolicy
 }
 
-func passesYearFilter(movie Movie, cfg config) bool {
+func (f yearFilter) Match(movie Movie, _ Rating, _ bool) bool {
 	if movie.StartYear == nil {
+		return f.missing != missingExclude
+	}
+	if f.missing == missingOnly {
 		return false
 	}
 
 	year := *movie.StartYear
-	return year >= cfg.minYear && year <= cfg.maxYear
+	return year >= f.min && year <= f.max
+}
+
+type runtimeFilter struct {
+	min, max int
+	missing  missingPolicy
 }
 
-func passesRuntimeFilter(movie Movie, cfg config) bool {
+func (f runtimeFilter) Match(movie Movie, _ Rating, _ bool) bool {
 	if movie.runtimeMinutes == nil {
+		return f.missing != missingExclude
+	}
+	if f.missing == missingOnly {
 		return false
 	}
 
 	runtime := *movie.runtimeMinutes
-	return runtime >= cfg.minRuntime && runtime <= cfg.maxRuntime
+	return runtime >= f.min && runtime <= f.max
+}
+
+type ratingFilter struct {
+	minRating          float64
+	minVotes, maxVotes int
+	minWeightedRating  float64
+	weights            ratingWeights
+	missing            missingPolicy
 }
 
-func passesRatingFilter(rating rating, hasRating bool, cfg config) bool {
+func (f ratingFilter) Match(_ Movie, rating Rating, hasRating bool) bool {
 	if !hasRating {
+		return f.missing != missingExclude
+	}
+	if f.missing == missingOnly {
 		return false
 	}
 
-	return rating.AverageRating >= cfg.minRating &&
-		rating.NumVotes >= cfg.minVotes && rating.NumVotes <= cfg.maxVotes
+	return rating.AverageRating >= f.minRating &&
+		rating.NumVotes >= f.minVotes && rating.NumVotes <= f.maxVotes &&
+		(f.minWeightedRating == 0 || f.weights.weightedRating(rating) >= f.minWeightedRating)
+}
+
+type genreFilter struct {
+	genres []string
 }
 
-func passesGenreFilter(movie Movie, cfg config) bool {
-	if len(cfg.genres) == 0 {
+func (f genreFilter) Match(movie Movie, _ Rating, _ bool) bool {
+	if len(f.genres) == 0 {
 		return true
 	}
 
-	return hasGenre(movie.Genres, cfg.genres)
+	return hasGenre(movie.Genres, f.genres)
+}
+
+type queryFilter struct {
+	query   *Query
+	weights ratingWeights
+}
+
+func (f queryFilter) Match(movie Movie, rating Rating, hasRating bool) bool {
+	return f.query.matches(movie, rating, hasRating, f.weights)
 }
 
 func randomizeResults(movies []Movie) {
@@ -131,6 +209,16 @@ func mapToSlice(movies map[string]Movie) []Movie {
 	return slice
 }
 
+// numWorkers defaults to one worker per CPU, overridden by IMDB_SEARCH_WORKERS
+func numWorkers() int {
+	if altWorkers := os.Getenv("IMDB_SEARCH_WORKERS"); altWorkers != "" {
+		if num, err := strconv.Atoi(altWorkers); err == nil {
+			return num
+		}
+	}
+	return runtime.NumCPU()
+}
+
 func partitionSlice(movies []Movie, numWorkers int) [][]Movie {
 	if len(movies) == 0 {
 		return nil
@@ -158,24 +246,14 @@ func collectFromChannel(ch chan Movie) []Movie {
 	return results
 }
 
+// hasGenre ignores case, so that genres such as Sci-Fi match however they are typed
 func hasGenre(movieGenres, filterGenres []string) bool {
-	genreSet := make(map[string]bool)
-	for _, g := range movieGenres {
-		genreSet[g] = true
-	}
-
 	for _, g := range filterGenres {
-		if genreSet[normalizeGenre(g)] {
+		if slices.ContainsFunc(movieGenres, func(genre string) bool {
+			return strings.EqualFold(genre, g)
+		}) {
 			return true
 		}
 	}
 	return false
 }
-
-func normalizeGenre(genre string) string {
-	if genre == "" {
-		return ""
-	}
-	lower := strings.ToLower(genre)
-	return strings.ToUpper(string(lower[0])) + lower[1:]
-}
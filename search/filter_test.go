@@ -1,6 +1,8 @@
 package search
 
 import (
+	"context"
+	"errors"
 	"math"
 	"os"
 	"slices"
@@ -27,14 +29,14 @@ func createTestMovie(id, title string, isAdult bool, year, runtime int, genres [
 	}
 }
 
-func createTestRating(avgRating float64, numVotes int) rating {
-	return rating{
+func createTestRating(avgRating float64, numVotes int) Rating {
+	return Rating{
 		AverageRating: avgRating,
 		NumVotes:      numVotes,
 	}
 }
 
-func setupTestData() (map[string]Movie, map[string]rating) {
+func setupTestData() (map[string]Movie, map[string]Rating) {
 	os.Setenv(searchWorkersEnv, "")
 	movies := map[string]Movie{
 		"1": createTestMovie("1", "Action Hero", false, 2020, 120, []string{"Action"}),
@@ -47,7 +49,7 @@ func setupTestData() (map[string]Movie, map[string]rating) {
 		"8": createTestMovie("8", "Short Film", false, 2020, 45, []string{"Documentary"}),
 	}
 
-	ratings := map[string]rating{
+	ratings := map[string]Rating{
 		"1": createTestRating(8.5, 10000),
 		"2": createTestRating(7.8, 8000),
 		"3": createTestRating(9.0, 15000),
@@ -79,7 +81,7 @@ func TestFilterMovies_AdultFilter(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -101,6 +103,22 @@ func TestFilterMovies_AdultFilter(t *testing.T) {
 	}
 }
 
+func TestFilterMoviesContext_Cancelled(t *testing.T) {
+	movies, ratings := setupTestData()
+	config := DefaultConfig()
+	config.missingRating = missingInclude
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if results, err := filterMoviesContext(ctx, movies, ratings, config); err != nil || len(results) == 0 {
+		t.Fatalf("Expected results before cancelling, got %d (%v)", len(results), err)
+	}
+
+	cancel()
+	if results, err := filterMoviesContext(ctx, movies, ratings, config); !errors.Is(err, context.Canceled) || results != nil {
+		t.Errorf("Expected a cancelled run to stop without results, got %d (%v)", len(results), err)
+	}
+}
+
 func TestFilterMovies_AdultFilterOff(t *testing.T) {
 	const resultCount = 8
 	movies, ratings := setupTestData()
@@ -119,7 +137,7 @@ func TestFilterMovies_AdultFilterOff(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -156,7 +174,7 @@ func TestFilterMovies_YearFilter(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -202,7 +220,7 @@ func TestFilterMovies_RuntimeFilter(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -248,7 +266,7 @@ func TestFilterMovies_RatingFilter(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -295,7 +313,7 @@ func TestFilterMovies_GenreFilter(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -336,7 +354,7 @@ func TestFilterMovies_GenreFilterMultiple(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -357,6 +375,18 @@ func TestFilterMovies_GenreFilterMultiple(t *testing.T) {
 	}
 }
 
+func TestHasGenre_IgnoresCase(t *testing.T) {
+	genres := []string{"Horror", "Sci-Fi"}
+	for _, filter := range []string{"Sci-Fi", "sci-fi", "SCI-FI", "horror"} {
+		if !hasGenre(genres, []string{filter}) {
+			t.Errorf("Expected %q to match %v", filter, genres)
+		}
+	}
+	if hasGenre(genres, []string{"Sci"}) {
+		t.Error("Expected partial genre names not to match")
+	}
+}
+
 func TestFilterMovies_GenreFilterCaseSensitivity(t *testing.T) {
 	const resultCount = 6
 	const genre1 = "ACTION"
@@ -377,7 +407,7 @@ func TestFilterMovies_GenreFilterCaseSensitivity(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -416,7 +446,7 @@ func TestFilterMovies_CombinedFilters(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -455,7 +485,7 @@ func TestFilterMovies_NoResultsCase(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		filterFunc func(map[string]Movie, map[string]rating, config) []Movie
+		filterFunc func(map[string]Movie, map[string]Rating, config) []Movie
 	}{
 		{"Sync", FilterMoviesSync},
 		{"Async", FilterMovies},
@@ -556,6 +586,353 @@ func TestFilterMovies_ConsistencyWithSync(t *testing.T) {
 	}
 }
 
+func TestFilterMovies_TitleFilter(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	amelie := createTestMovie("9", "Amélie", false, 2001, 122, []string{"Comedy"})
+	amelie.originalTitle = "Le fabuleux destin d'Amélie Poulain"
+	movies["9"] = amelie
+	ratings["9"] = createTestRating(8.3, 800000)
+
+	nosferatu := createTestMovie("10", "Nosferatu", false, 1922, 94, []string{"Horror"})
+	nosferatu.akas = []string{"Nosferatu, eine Symphonie des Grauens"}
+	movies["10"] = nosferatu
+	ratings["10"] = createTestRating(7.8, 110000)
+
+	testCases := []struct {
+		name          string
+		query         string
+		mode          string
+		ignoreAccents bool
+		expectedIDs   []string
+	}{
+		{"Substring", "action", "substring", false, []string{"1", "5"}},
+		{"SubstringPartialWord", "act", "", false, []string{"1", "5"}},
+		{"Word", "act", "word", false, nil},
+		{"WordPhrase", "action hero", "word", false, []string{"1"}},
+		{"Regex", "^(old|long) ", "regex", false, []string{"6", "7"}},
+		{"AccentSensitive", "amelie", "substring", false, nil},
+		{"AccentInsensitive", "amelie", "substring", true, []string{"9"}},
+		{"OriginalTitle", "fabuleux destin", "word", false, []string{"9"}},
+		{"Akas", "symphonie", "substring", false, []string{"10"}},
+	}
+
+	for _, tc := range testCases {
+		title, err := newTitleSearch(tc.query, tc.mode, tc.ignoreAccents)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		cfg := config{
+			minYear:    0,
+			maxYear:    math.MaxInt,
+			minRuntime: 0,
+			maxRuntime: math.MaxInt,
+			maxVotes:   math.MaxInt,
+			title:      title,
+		}
+
+		for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+			results := filterFunc(movies, ratings, cfg)
+			if len(results) != len(tc.expectedIDs) {
+				t.Errorf("%s: expected %d results, got %d", tc.name, len(tc.expectedIDs), len(results))
+			}
+			for _, movie := range results {
+				if !slices.Contains(tc.expectedIDs, movie.Id) {
+					t.Errorf("%s: movie %s should not be in results", tc.name, movie.Id)
+				}
+			}
+		}
+	}
+}
+
+func TestNewTitleSearch_RegexEscapes(t *testing.T) {
+	titles := []string{"Alien", "Alien 3", "Alien Resurrection"}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{`^alien \D`, []string{"Alien Resurrection"}},
+		{`^alien \d$`, []string{"Alien 3"}},
+		{`^\S+$`, []string{"Alien"}},
+		{`\Bsurrect`, []string{"Alien Resurrection"}},
+	}
+
+	for _, tt := range tests {
+		title, err := newTitleSearch(tt.pattern, "regex", true)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", tt.pattern, err)
+		}
+		var matched []string
+		for i, name := range titles {
+			if title.Match(createTestMovie(string(rune('a'+i)), name, false, 1979, 117, nil), Rating{}, false) {
+				matched = append(matched, name)
+			}
+		}
+		if !slices.Equal(matched, tt.expected) {
+			t.Errorf("Expected %s to match %v, got %v", tt.pattern, tt.expected, matched)
+		}
+	}
+
+	if _, err := newTitleSearch(`\P{Lu}`, "regex", false); err != nil {
+		t.Errorf("Expected a Unicode class to compile, got %v", err)
+	}
+}
+
+func TestNewTitleSearch_Invalid(t *testing.T) {
+	if _, err := newTitleSearch("(", "regex", false); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if _, err := newTitleSearch("alien", "fuzzy", false); err == nil {
+		t.Error("Expected error for unknown match mode")
+	}
+}
+
+func TestFilterMovies_WeightedRatingFilter(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	movies["9"] = createTestMovie("9", "Tiny Gem", false, 2020, 100, []string{"Drama"})
+	ratings["9"] = createTestRating(9.4, 12)
+
+	cfg := config{
+		maxYear:           math.MaxInt,
+		maxRuntime:        math.MaxInt,
+		maxVotes:          math.MaxInt,
+		minWeightedRating: 7.9,
+		weights:           ratingWeights{priorMean: 7.0, minVotes: 1000},
+	}
+
+	var expectedIDs = map[string]bool{"1": true, "3": true, "5": true, "7": true}
+
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if len(results) != len(expectedIDs) {
+			t.Errorf("Expected %d results, got %d", len(expectedIDs), len(results))
+		}
+		for _, movie := range results {
+			if !expectedIDs[movie.Id] {
+				t.Errorf("Movie %s should not be in results", movie.Id)
+			}
+		}
+	}
+}
+
+func TestFilterMovies_SortByWeightedRating(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	movies["9"] = createTestMovie("9", "Tiny Gem", false, 2020, 100, []string{"Drama"})
+	ratings["9"] = createTestRating(9.4, 12)
+
+	cfg := config{
+		maxYear:    math.MaxInt,
+		maxRuntime: math.MaxInt,
+		maxVotes:   math.MaxInt,
+		sortBy:     sortWeightedRating,
+		weights:    ratingWeights{minVotes: 1000},
+	}
+
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if results[0].Id != "3" {
+			t.Errorf("Expected 3 to have the highest weighted Rating, got %s", results[0].Id)
+		}
+
+		weights := cfg.weights.resolve(ratings)
+		for i := 1; i < len(results); i++ {
+			if weights.weightedRating(ratings[results[i].Id]) > weights.weightedRating(ratings[results[i-1].Id]) {
+				t.Errorf("Results are not sorted by weighted rating at index %d", i)
+			}
+		}
+	}
+}
+
+func TestFilterMovies_WeightedSample(t *testing.T) {
+	const runs = 100
+	movies, ratings := setupTestData()
+
+	cfg := config{
+		maxYear:        math.MaxInt,
+		maxRuntime:     math.MaxInt,
+		maxVotes:       math.MaxInt,
+		sortBy:         sortSample,
+		sampleBy:       sampleByRating,
+		sampleStrength: 100,
+	}
+
+	// Weights raised to 1000 overflow a float64, which must not make every key equal
+	for _, strength := range []float64{100, 1000} {
+		cfg.sampleStrength = strength
+		topCount := 0
+		for range runs {
+			results := FilterMovies(movies, ratings, cfg)
+			if len(results) != len(movies) {
+				t.Fatalf("Expected sampling to keep all %d results, got %d", len(movies), len(results))
+			}
+			if results[0].Id == "3" {
+				topCount++
+			}
+		}
+
+		if topCount < runs*9/10 {
+			t.Errorf("Expected the highest rated movie first in most runs at strength %v, got %d of %d", strength, topCount, runs)
+		}
+	}
+}
+
+func TestRatingWeights_WeightedRating(t *testing.T) {
+	weights := ratingWeights{priorMean: 7.0, minVotes: 1000}
+
+	if wr := weights.weightedRating(createTestRating(9.0, 0)); wr != 7.0 {
+		t.Errorf("Expected rating without votes to equal the prior mean, got %v", wr)
+	}
+	if wr := weights.weightedRating(createTestRating(9.0, 1000)); wr != 8.0 {
+		t.Errorf("Expected equal votes and minimum votes to average the ratings, got %v", wr)
+	}
+	if wr := (ratingWeights{priorMean: 7.0}).weightedRating(createTestRating(9.0, 10)); wr != 9.0 {
+		t.Errorf("Expected zero minimum votes to keep the raw Rating, got %v", wr)
+	}
+
+	_, ratings := setupTestData()
+	resolved := ratingWeights{minVotes: 1000}.resolve(ratings)
+	if resolved.priorMean != meanRating(ratings) {
+		t.Errorf("Expected prior mean to be resolved from the dataset, got %v", resolved.priorMean)
+	}
+}
+
+func TestFilterMovies_MissingDataPolicies(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	noYear := createTestMovie("9", "Lost Reel", false, 0, 80, []string{"Drama"})
+	noYear.StartYear = nil
+	movies["9"] = noYear
+	ratings["9"] = createTestRating(6.0, 100)
+
+	noRuntime := createTestMovie("10", "Unknown Length", false, 2010, 0, []string{"Drama"})
+	noRuntime.runtimeMinutes = nil
+	movies["10"] = noRuntime
+	ratings["10"] = createTestRating(6.5, 200)
+
+	movies["11"] = createTestMovie("11", "Obscure Short", false, 2015, 12, []string{"Drama"})
+
+	testCases := []struct {
+		name           string
+		missingYear    missingPolicy
+		missingRuntime missingPolicy
+		missingRating  missingPolicy
+		expectedCount  int
+		expectedIDs    []string
+	}{
+		{"ExcludeAll", missingExclude, missingExclude, missingExclude, 8, nil},
+		{"IncludeYear", missingInclude, missingExclude, missingExclude, 9, []string{"9"}},
+		{"IncludeRuntime", missingExclude, missingInclude, missingExclude, 9, []string{"10"}},
+		{"IncludeRating", missingExclude, missingExclude, missingInclude, 9, []string{"11"}},
+		{"IncludeAll", missingInclude, missingInclude, missingInclude, 11, []string{"9", "10", "11"}},
+		{"OnlyYear", missingOnly, missingExclude, missingExclude, 1, []string{"9"}},
+		{"OnlyRuntime", missingExclude, missingOnly, missingExclude, 1, []string{"10"}},
+		{"OnlyRating", missingExclude, missingExclude, missingOnly, 1, []string{"11"}},
+	}
+
+	for _, tc := range testCases {
+		cfg := config{
+			maxYear:        math.MaxInt,
+			maxRuntime:     math.MaxInt,
+			maxVotes:       math.MaxInt,
+			missingYear:    tc.missingYear,
+			missingRuntime: tc.missingRuntime,
+			missingRating:  tc.missingRating,
+		}
+
+		for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+			results := filterFunc(movies, ratings, cfg)
+			if len(results) != tc.expectedCount {
+				t.Errorf("%s: expected %d results, got %d", tc.name, tc.expectedCount, len(results))
+			}
+
+			ids := make([]string, 0, len(results))
+			for _, movie := range results {
+				ids = append(ids, movie.Id)
+			}
+			for _, id := range tc.expectedIDs {
+				if !slices.Contains(ids, id) {
+					t.Errorf("%s: expected movie %s in results", tc.name, id)
+				}
+			}
+		}
+	}
+}
+
+type fakeHistory map[string]bool
+
+func (h fakeHistory) Seen(id string) bool           { return h[id] }
+func (h fakeHistory) MarkWatched(id string) error   { h[id] = true; return nil }
+func (h fakeHistory) MarkDismissed(id string) error { h[id] = true; return nil }
+
+func TestFilterMovies_HistoryFilter(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	cfg := config{
+		maxYear:    math.MaxInt,
+		maxRuntime: math.MaxInt,
+		maxVotes:   math.MaxInt,
+	}
+	cfg.SetHistory(fakeHistory{"1": true, "3": true})
+
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if len(results) != 6 {
+			t.Errorf("Expected 6 results, got %d", len(results))
+		}
+		for _, movie := range results {
+			if movie.Id == "1" || movie.Id == "3" {
+				t.Errorf("Movie %s has been seen and should not be in results", movie.Id)
+			}
+		}
+	}
+
+	cfg.includeSeen = true
+	if results := FilterMovies(movies, ratings, cfg); len(results) != 8 {
+		t.Errorf("Expected seen movies to be included, got %d results", len(results))
+	}
+}
+
+type fakeWatchlist map[string]bool
+
+func (w fakeWatchlist) OnWatchlist(id string) bool            { return w[id] }
+func (w fakeWatchlist) AddToWatchlist(id, title string) error { w[id] = true; return nil }
+
+func TestFilterMovies_WatchlistOnly(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	cfg := config{
+		maxYear:    math.MaxInt,
+		maxRuntime: math.MaxInt,
+		maxVotes:   math.MaxInt,
+	}
+	cfg.SetWatchlist(fakeWatchlist{"2": true, "4": true})
+
+	if results := FilterMovies(movies, ratings, cfg); len(results) != 8 {
+		t.Errorf("Expected the watchlist to be ignored unless WatchlistOnly is set, got %d results", len(results))
+	}
+
+	cfg.WatchlistOnly = true
+	cfg.minRating = 7.0
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if len(results) != 1 {
+			t.Errorf("Expected 1 result, got %d", len(results))
+		}
+		for _, movie := range results {
+			if movie.Id != "2" && movie.Id != "4" {
+				t.Errorf("Movie %s is not on the watchlist and should not be in results", movie.Id)
+			}
+			if ratings[movie.Id].AverageRating < 7.0 {
+				t.Errorf("Movie %s should still be filtered by rating", movie.Id)
+			}
+		}
+	}
+}
+
 // Benchmark helpers
 func generateTestMovies(n int) map[string]Movie {
 	movies := make(map[string]Movie)
@@ -577,8 +954,8 @@ func generateTestMovies(n int) map[string]Movie {
 	return movies
 }
 
-func generateTestRatings(n int) map[string]rating {
-	ratings := make(map[string]rating)
+func generateTestRatings(n int) map[string]Rating {
+	ratings := make(map[string]Rating)
 
 	for i := 0; i < n; i++ {
 		id := string(rune('a'+(i%26))) + string(rune('0'+(i/26)))
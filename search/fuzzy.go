@@ -0,0 +1,187 @@
+package search
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	trigramPadding     = "  "
+	fuzzyCandidatePool = 200
+)
+
+type TitleMatch struct {
+	Movie Movie
+	Title string  // The title that matched, may be an original or alternative title
+	Score float64 // Similarity between 0 and 1, higher is better
+}
+
+// TitleIndex is a trigram index over every known title of a set of movies
+type TitleIndex struct {
+	movies   []Movie
+	entries  []titleEntry
+	postings map[string][]int32
+}
+
+type titleEntry struct {
+	movie      int32
+	title      string
+	normalized string
+	trigrams   int
+}
+
+func NewTitleIndex(movies map[string]Movie) *TitleIndex {
+	index := &TitleIndex{
+		movies:   mapToSlice(movies),
+		postings: make(map[string][]int32),
+	}
+
+	for i, movie := range index.movies {
+		seen := make(map[string]bool)
+		titles := append([]string{movie.PrimaryTitle, movie.originalTitle}, movie.akas...)
+		for _, title := range titles {
+			normalized := normalizeFuzzy(title)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+
+			entryId := int32(len(index.entries))
+			grams := trigrams(normalized)
+			for _, gram := range grams {
+				index.postings[gram] = append(index.postings[gram], entryId)
+			}
+			index.entries = append(index.entries, titleEntry{
+				movie:      int32(i),
+				title:      title,
+				normalized: normalized,
+				trigrams:   len(grams),
+			})
+		}
+	}
+
+	return index
+}
+
+// Search returns up to limit movies whose titles resemble query, best match first
+func (idx *TitleIndex) Search(query string, limit int) []TitleMatch {
+	normalized := normalizeFuzzy(query)
+	if normalized == "" || limit <= 0 {
+		return nil
+	}
+
+	queryGrams := trigrams(normalized)
+	shared := make(map[int32]int)
+	for _, gram := range queryGrams {
+		for _, entryId := range idx.postings[gram] {
+			shared[entryId]++
+		}
+	}
+
+	candidates := make([]int32, 0, len(shared))
+	for entryId := range shared {
+		candidates = append(candidates, entryId)
+	}
+	slices.SortFunc(candidates, func(a, b int32) int {
+		return compareDesc(dice(shared[a], len(queryGrams), idx.entries[a].trigrams),
+			dice(shared[b], len(queryGrams), idx.entries[b].trigrams))
+	})
+	if len(candidates) > fuzzyCandidatePool {
+		candidates = candidates[:fuzzyCandidatePool]
+	}
+
+	best := make(map[int32]TitleMatch)
+	for _, entryId := range candidates {
+		entry := idx.entries[entryId]
+		score := (dice(shared[entryId], len(queryGrams), entry.trigrams) +
+			editSimilarity(normalized, entry.normalized)) / 2
+
+		if existing, ok := best[entry.movie]; !ok || score > existing.Score {
+			best[entry.movie] = TitleMatch{
+				Movie: idx.movies[entry.movie],
+				Title: entry.title,
+				Score: score,
+			}
+		}
+	}
+
+	matches := make([]TitleMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, match)
+	}
+	slices.SortFunc(matches, func(a, b TitleMatch) int {
+		if c := compareDesc(a.Score, b.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Movie.Id, b.Movie.Id)
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func normalizeFuzzy(title string) string {
+	return strings.Join(titleWords(normalizeTitle(title, true)), " ")
+}
+
+func trigrams(normalized string) []string {
+	padded := []rune(trigramPadding + normalized + " ")
+	seen := make(map[string]bool, len(padded))
+	grams := make([]string, 0, len(padded))
+	for i := 0; i+3 <= len(padded); i++ {
+		gram := string(padded[i : i+3])
+		if !seen[gram] {
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+func dice(shared, a, b int) float64 {
+	if a+b == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(a+b)
+}
+
+// editSimilarity is the Levenshtein distance scaled to a 0-1 similarity
+func editSimilarity(a, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+	longest := max(len(ar), len(br))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(br)])/float64(longest)
+}
+
+func compareDesc(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
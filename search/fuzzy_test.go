@@ -0,0 +1,69 @@
+package search
+
+import "testing"
+
+func TestTitleIndex_Search(t *testing.T) {
+	movies, _ := setupTestData()
+
+	godfather := createTestMovie("9", "The Godfather", false, 1972, 175, []string{"Crime", "Drama"})
+	movies["9"] = godfather
+
+	amelie := createTestMovie("10", "Amélie", false, 2001, 122, []string{"Comedy"})
+	amelie.originalTitle = "Le fabuleux destin d'Amélie Poulain"
+	movies["10"] = amelie
+
+	index := NewTitleIndex(movies)
+
+	testCases := []struct {
+		name       string
+		query      string
+		expectedId string
+	}{
+		{"Typo", "Godfater", "9"},
+		{"MissingAccent", "Amelie", "10"},
+		{"OriginalTitle", "fabuleux destin amelie", "10"},
+		{"ExactTitle", "Thriller Night", "3"},
+		{"CaseAndPunctuation", "action-HERO!", "1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := index.Search(tc.query, 3)
+			if len(matches) == 0 {
+				t.Fatalf("Expected matches for %q, got none", tc.query)
+			}
+			if matches[0].Movie.Id != tc.expectedId {
+				t.Errorf("Expected %s as best match for %q, got %s", tc.expectedId, tc.query, matches[0].Movie.Id)
+			}
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Score > matches[i-1].Score {
+					t.Errorf("Matches for %q are not ranked by score", tc.query)
+				}
+			}
+		})
+	}
+}
+
+func TestTitleIndex_SearchLimit(t *testing.T) {
+	movies, _ := setupTestData()
+	index := NewTitleIndex(movies)
+
+	if matches := index.Search("action", 1); len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %d", len(matches))
+	}
+	if matches := index.Search("   ", 5); len(matches) != 0 {
+		t.Errorf("Expected no matches for blank query, got %d", len(matches))
+	}
+}
+
+func TestEditSimilarity(t *testing.T) {
+	if sim := editSimilarity("godfather", "godfather"); sim != 1 {
+		t.Errorf("Expected identical strings to have similarity 1, got %v", sim)
+	}
+	if sim := editSimilarity("godfater", "godfather"); sim < 0.85 {
+		t.Errorf("Expected single typo to have high similarity, got %v", sim)
+	}
+	if sim := editSimilarity("abc", "xyz"); sim != 0 {
+		t.Errorf("Expected unrelated strings to have similarity 0, got %v", sim)
+	}
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Participant is one person's settings in a group search
+type Participant struct {
+	Name   string
+	Config config
+}
+
+// GroupMatch is a title the whole group can watch, with the participants whose
+// genre preferences it meets
+type GroupMatch struct {
+	Movie     Movie
+	Satisfied []string
+}
+
+// GroupResult ranks the titles a group can watch together, best first
+type GroupResult struct {
+	Participants []string
+	Matches      []GroupMatch
+	ratings      map[string]Rating
+	weights      ratingWeights
+}
+
+// GroupSearch finds titles for several participants at once. Every participant's
+// settings must hold, so their limits intersect and their exclusions add up, and
+// titles any of them has watched or dismissed are left out even if they usually
+// include seen titles. Genres are preferences rather than limits: titles are
+// ranked by how many participants get one of their genres, then by weighted
+// rating.
+func GroupSearch(movies map[string]Movie, ratings map[string]Rating, participants []Participant) *GroupResult {
+	group := groupConfig()
+	required := make([]Filter, len(participants))
+	names := make([]string, len(participants))
+	for i, participant := range participants {
+		cfg := participant.Config.resolve(ratings)
+		cfg.genres = nil
+		cfg.includeSeen = false
+		required[i] = cfg.filter()
+		names[i] = participant.Name
+	}
+	group.AddFilter(And(required...))
+
+	results := FilterMovies(movies, ratings, group)
+	matches := make([]GroupMatch, len(results))
+	for i, movie := range results {
+		matches[i].Movie = movie
+		for _, participant := range participants {
+			if (genreFilter{genres: participant.Config.genres}).Match(movie, Rating{}, false) {
+				matches[i].Satisfied = append(matches[i].Satisfied, participant.Name)
+			}
+		}
+	}
+	// Results are already by weighted rating, which a stable sort keeps within each score
+	slices.SortStableFunc(matches, func(a, b GroupMatch) int {
+		return len(b.Satisfied) - len(a.Satisfied)
+	})
+
+	return &GroupResult{
+		Participants: names,
+		Matches:      matches,
+		ratings:      ratings,
+		weights:      group.weights.resolve(ratings),
+	}
+}
+
+// groupConfig matches every title, leaving the participants' settings to decide,
+// and orders results by weighted rating
+func groupConfig() config {
+	return config{
+		maxYear:        math.MaxInt,
+		maxRuntime:     math.MaxInt,
+		maxVotes:       math.MaxInt,
+		missingYear:    missingInclude,
+		missingRuntime: missingInclude,
+		missingRating:  missingInclude,
+		sortBy:         sortWeightedRating,
+		weights:        DefaultConfig().weights,
+	}
+}
+
+// Movies returns the matched titles in rank order, e.g. for browsing
+func (r *GroupResult) Movies() []Movie {
+	movies := make([]Movie, len(r.Matches))
+	for i, match := range r.Matches {
+		movies[i] = match.Movie
+	}
+	return movies
+}
+
+// Print lists up to limit of the best matches with who each one suits
+func (r *GroupResult) Print(w io.Writer, limit int) {
+	fmt.Fprintf(w, "%d titles suit %s\n", len(r.Matches), strings.Join(r.Participants, ", "))
+
+	for i, match := range r.Matches[:max(min(limit, len(r.Matches)), 0)] {
+		rating, hasRating := r.ratings[match.Movie.Id]
+		fmt.Fprintf(w, "%3d) %s\n", i+1, formatSummary(match.Movie, rating, hasRating, r.weights))
+
+		suits := "no one's genres"
+		if len(match.Satisfied) > 0 {
+			suits = strings.Join(match.Satisfied, ", ")
+		}
+		fmt.Fprintf(w, "     %d/%d preferences: %s\n", len(match.Satisfied), len(r.Participants), suits)
+	}
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupSearch(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	alice, err := ConfigFromSettings(map[string]string{"minYear": "2015", "genres": "Documentary,Action", "includeSeen": "yes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.SetHistory(fakeHistory{"5": true})
+	bob, err := ConfigFromSettings(map[string]string{"maxRuntime": "150", "genres": "Documentary", "excludeAdult": "yes", "query": "not genre:comedy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := GroupSearch(movies, ratings, []Participant{{"alice", alice}, {"bob", bob}})
+
+	var ids []string
+	for _, movie := range result.Movies() {
+		ids = append(ids, movie.Id)
+	}
+	// 2 is adult, 4 a comedy, 5 seen by alice, 6 too old and 7 too long. The
+	// documentary suits both, ahead of the better rated titles which suit one.
+	if expected := []string{"8", "3", "1"}; !slices.Equal(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	if satisfied := result.Matches[0].Satisfied; !slices.Equal(satisfied, []string{"alice", "bob"}) {
+		t.Errorf("Expected the documentary to suit alice and bob, got %v", satisfied)
+	}
+
+	var out strings.Builder
+	result.Print(&out, 2)
+	for _, expected := range []string{"3 titles suit alice, bob", "1) Short Film (2020)", "2/2 preferences: alice, bob", "1/2 preferences: alice"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Action Hero") {
+		t.Errorf("Expected only 2 titles to be printed:\n%s", out.String())
+	}
+
+	out.Reset()
+	result.Print(&out, -1)
+	if strings.Contains(out.String(), "1)") {
+		t.Errorf("Expected a negative limit to print no titles:\n%s", out.String())
+	}
+}
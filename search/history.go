@@ -0,0 +1,26 @@
+package search
+
+// History remembers titles the user has watched or is not interested in. It is
+// consulted by the FilterMovies worker pool, so it must be safe for concurrent use.
+type History interface {
+	Seen(id string) bool
+	MarkWatched(id string) error
+	MarkDismissed(id string) error
+}
+
+type historyFilter struct {
+	history History
+}
+
+func (f historyFilter) Match(movie Movie, _ Rating, _ bool) bool {
+	return !f.history.Seen(movie.Id)
+}
+
+func (f historyFilter) name() string { return "history" }
+
+func (f historyFilter) reason(movie Movie, _ Rating, _ bool) string {
+	if f.history.Seen(movie.Id) {
+		return "already watched or dismissed"
+	}
+	return "not watched or dismissed yet"
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -93,7 +94,7 @@ func LoadMovies(filename string) (map[string]Movie, error) {
 	return movies, nil
 }
 
-func LoadRatings(filename string) (map[string]rating, error) {
+func LoadRatings(filename string) (map[string]Rating, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("opening file: %w", err)
@@ -103,7 +104,7 @@ func LoadRatings(filename string) (map[string]rating, error) {
 	reader := csv.NewReader(file)
 	reader.Comma = tabComma
 
-	ratings := make(map[string]rating)
+	ratings := make(map[string]Rating)
 
 	header, err := reader.Read()
 	if err != nil {
@@ -140,7 +141,7 @@ func LoadRatings(filename string) (map[string]rating, error) {
 		avgRating, _ := strconv.ParseFloat(record[colIndex["averageRating"]], 64)
 		numVotes, _ := strconv.Atoi(record[colIndex["numVotes"]])
 
-		ratings[record[colIndex["tconst"]]] = rating{
+		ratings[record[colIndex["tconst"]]] = Rating{
 			id:            record[colIndex["tconst"]],
 			AverageRating: avgRating,
 			NumVotes:      numVotes,
@@ -150,6 +151,67 @@ func LoadRatings(filename string) (map[string]rating, error) {
 	return ratings, nil
 }
 
+// LoadAkas attaches alternative titles from a title.akas file to already loaded movies
+func LoadAkas(filename string, movies map[string]Movie) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = tabComma
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+
+	colIndex := make(map[string]int)
+	for i, col := range header {
+		colIndex[col] = i
+	}
+
+	requiredCols := []string{"titleId", "title"}
+	for _, col := range requiredCols {
+		if _, exists := colIndex[col]; !exists {
+			return fmt.Errorf("required column '%s' not found in file", col)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Omitting record due to error on loadAkas:", err)
+			continue
+		}
+
+		if len(record) < len(header) {
+			log.Println("loadAkas: len(record) < len(header):", err)
+			continue
+		}
+
+		movie, exists := movies[record[colIndex["titleId"]]]
+		if !exists {
+			continue
+		}
+
+		title := record[colIndex["title"]]
+		if title == movie.PrimaryTitle || title == movie.originalTitle || slices.Contains(movie.akas, title) {
+			continue
+		}
+
+		movie.akas = append(movie.akas, title)
+		movies[movie.Id] = movie
+	}
+
+	return nil
+}
+
 func validMovie(titleType string) bool {
 	return titleType == "movie" || titleType == "short" || titleType == "tvMovie" || titleType == "tvShort"
 }
@@ -0,0 +1,100 @@
+package search
+
+const (
+	fuzzyMatchThreshold = 0.6
+	matchYearTolerance  = 1
+	matchCandidates     = 20
+)
+
+type MatchKind int
+
+const (
+	NoMatch MatchKind = iota
+	ExactMatch
+	FuzzyMatch
+)
+
+// TitleMatcher resolves a title and year from another service to an IMDB movie
+type TitleMatcher struct {
+	data    *Dataset
+	index   *TitleIndex
+	byTitle map[string][]string
+}
+
+func NewTitleMatcher(data *Dataset) *TitleMatcher {
+	matcher := &TitleMatcher{
+		data:    data,
+		index:   NewTitleIndex(data.Movies),
+		byTitle: make(map[string][]string),
+	}
+
+	for id, movie := range data.Movies {
+		seen := make(map[string]bool)
+		for _, title := range append([]string{movie.PrimaryTitle, movie.originalTitle}, movie.akas...) {
+			normalized := normalizeFuzzy(title)
+			if normalized != "" && !seen[normalized] {
+				seen[normalized] = true
+				matcher.byTitle[normalized] = append(matcher.byTitle[normalized], id)
+			}
+		}
+	}
+
+	return matcher
+}
+
+// Match prefers a title matching exactly in the same year, then allows the year
+// to be off by one and finally falls back to a fuzzy title match. A year of zero
+// matches any year. Ties go to the most voted title.
+func (m *TitleMatcher) Match(title string, year int) (Movie, MatchKind) {
+	ids := m.byTitle[normalizeFuzzy(title)]
+	if movie, found := m.mostVoted(ids, year, 0); found {
+		return movie, ExactMatch
+	}
+	if movie, found := m.mostVoted(ids, year, matchYearTolerance); found {
+		return movie, FuzzyMatch
+	}
+
+	var best TitleMatch
+	for _, match := range m.index.Search(title, matchCandidates) {
+		if match.Score < fuzzyMatchThreshold || !yearMatches(match.Movie, year, matchYearTolerance) {
+			continue
+		}
+		if best.Movie.Id == "" || match.Score > best.Score ||
+			(match.Score == best.Score && m.votes(match.Movie.Id) > m.votes(best.Movie.Id)) {
+			best = match
+		}
+	}
+	if best.Movie.Id == "" {
+		return Movie{}, NoMatch
+	}
+	return best.Movie, FuzzyMatch
+}
+
+func (m *TitleMatcher) mostVoted(ids []string, year, tolerance int) (Movie, bool) {
+	var best Movie
+	found := false
+	for _, id := range ids {
+		movie := m.data.Movies[id]
+		if !yearMatches(movie, year, tolerance) {
+			continue
+		}
+		if !found || m.votes(id) > m.votes(best.Id) {
+			best, found = movie, true
+		}
+	}
+	return best, found
+}
+
+func (m *TitleMatcher) votes(id string) int {
+	return m.data.Ratings[id].NumVotes
+}
+
+func yearMatches(movie Movie, year, tolerance int) bool {
+	if year == 0 {
+		return true
+	}
+	if movie.StartYear == nil {
+		return false
+	}
+	return *movie.StartYear >= year-tolerance && *movie.StartYear <= year+tolerance
+}
@@ -0,0 +1,41 @@
+package search
+
+import "testing"
+
+func TestTitleMatcher_Match(t *testing.T) {
+	movies, ratings := setupTestData()
+	movies["9"] = createTestMovie("9", "Action Hero", false, 1988, 95, []string{"Action"})
+	ratings["9"] = createTestRating(5.0, 50)
+	movies["10"] = createTestMovie("10", "Action Hero", false, 2020, 10, []string{"Action"})
+	ratings["10"] = createTestRating(6.0, 10)
+
+	matcher := NewTitleMatcher(&Dataset{Movies: movies, Ratings: ratings})
+
+	testCases := []struct {
+		name       string
+		title      string
+		year       int
+		expectedId string
+		kind       MatchKind
+	}{
+		{"ExactTitleAndYear", "Action Hero", 1988, "9", ExactMatch},
+		{"MostVotedOnTie", "action hero", 2020, "1", ExactMatch},
+		{"AnyYear", "Action Hero", 0, "1", ExactMatch},
+		{"YearOffByOne", "Old Classic", 2006, "6", FuzzyMatch},
+		{"FuzzyTitle", "Thriler Nite", 2021, "3", FuzzyMatch},
+		{"WrongYear", "Old Classic", 1950, "", NoMatch},
+		{"Unknown", "Completely Different", 2020, "", NoMatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			movie, kind := matcher.Match(tc.title, tc.year)
+			if kind != tc.kind {
+				t.Errorf("Expected match kind %v, got %v", tc.kind, kind)
+			}
+			if movie.Id != tc.expectedId {
+				t.Errorf("Expected %q, got %q", tc.expectedId, movie.Id)
+			}
+		})
+	}
+}
@@ -10,4 +10,5 @@ type Movie struct {
 	endYear        *int
 	runtimeMinutes *int // Pointer to allow nil for missing data
 	Genres         []string
+	akas           []string
 }
@@ -0,0 +1,55 @@
+package search
+
+// Filter decides whether a movie belongs in the results. Filters are shared by
+// the FilterMovies worker pool, so Match must be safe for concurrent use.
+type Filter interface {
+	Match(movie Movie, rating Rating, hasRating bool) bool
+}
+
+// FilterFunc adapts an ordinary function to a Filter
+type FilterFunc func(movie Movie, rating Rating, hasRating bool) bool
+
+func (f FilterFunc) Match(movie Movie, rating Rating, hasRating bool) bool {
+	return f(movie, rating, hasRating)
+}
+
+type andFilter []Filter
+type orFilter []Filter
+type notFilter struct{ filter Filter }
+
+// And matches movies matched by every filter, or all movies when given none
+func And(filters ...Filter) Filter {
+	return andFilter(filters)
+}
+
+// Or matches movies matched by at least one filter, or no movies when given none
+func Or(filters ...Filter) Filter {
+	return orFilter(filters)
+}
+
+// Not matches movies which filter does not match
+func Not(filter Filter) Filter {
+	return notFilter{filter}
+}
+
+func (f andFilter) Match(movie Movie, rating Rating, hasRating bool) bool {
+	for _, filter := range f {
+		if !filter.Match(movie, rating, hasRating) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f orFilter) Match(movie Movie, rating Rating, hasRating bool) bool {
+	for _, filter := range f {
+		if filter.Match(movie, rating, hasRating) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f notFilter) Match(movie Movie, rating Rating, hasRating bool) bool {
+	return !f.filter.Match(movie, rating, hasRating)
+}
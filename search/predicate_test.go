@@ -0,0 +1,96 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFilterCombinators(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	isAction := FilterFunc(func(movie Movie, _ Rating, _ bool) bool {
+		return hasGenre(movie.Genres, []string{"Action"})
+	})
+	isRecent := FilterFunc(func(movie Movie, _ Rating, _ bool) bool {
+		return *movie.StartYear >= 2021
+	})
+
+	testCases := []struct {
+		name        string
+		filter      Filter
+		expectedIDs map[string]bool
+	}{
+		{"And", And(isAction, isRecent), map[string]bool{"3": true, "5": true}},
+		{"Or", Or(isAction, isRecent), map[string]bool{"1": true, "3": true, "5": true}},
+		{"Not", Not(isAction), map[string]bool{"2": true, "4": true, "6": true, "7": true, "8": true}},
+		{"NotAnd", Not(And(isAction, isRecent)), map[string]bool{"1": true, "2": true, "4": true, "6": true, "7": true, "8": true}},
+		{"EmptyAnd", And(), map[string]bool{"1": true, "2": true, "3": true, "4": true, "5": true, "6": true, "7": true, "8": true}},
+		{"EmptyOr", Or(), map[string]bool{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched := 0
+			for _, movie := range movies {
+				rating, hasRating := ratings[movie.Id]
+				if tc.filter.Match(movie, rating, hasRating) {
+					matched++
+					if !tc.expectedIDs[movie.Id] {
+						t.Errorf("Movie %s should not match", movie.Id)
+					}
+				}
+			}
+			if matched != len(tc.expectedIDs) {
+				t.Errorf("Expected %d matches, got %d", len(tc.expectedIDs), matched)
+			}
+		})
+	}
+}
+
+func TestFilterMovies_CustomFilter(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	cfg := config{
+		maxYear:    math.MaxInt,
+		maxRuntime: math.MaxInt,
+		maxVotes:   math.MaxInt,
+		genres:     []string{"Action", "Drama"},
+	}
+	cfg.AddFilter(FilterFunc(func(movie Movie, rating Rating, hasRating bool) bool {
+		return hasRating && rating.NumVotes >= 10000
+	}))
+	cfg.AddFilter(Not(FilterFunc(func(movie Movie, _ Rating, _ bool) bool {
+		return strings.HasPrefix(movie.PrimaryTitle, "Old")
+	})))
+
+	var expectedIDs = map[string]bool{"1": true, "3": true, "5": true}
+
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if len(results) != len(expectedIDs) {
+			t.Errorf("Expected %d results, got %d", len(expectedIDs), len(results))
+		}
+		for _, movie := range results {
+			if !expectedIDs[movie.Id] {
+				t.Errorf("Movie %s should not be in results", movie.Id)
+			}
+		}
+	}
+}
+
+func TestConfig_AddFilterDoesNotAlias(t *testing.T) {
+	base := DefaultConfig()
+	base.AddFilter(And())
+
+	first, second := base, base
+	first.AddFilter(Or())
+	second.AddFilter(Not(Or()))
+
+	if _, ok := first.filters[1].(orFilter); !ok {
+		t.Error("Adding a filter to a copy overwrote another copy's filters")
+	}
+	if len(base.filters) != 1 {
+		t.Errorf("Expected base config to keep 1 filter, got %d", len(base.filters))
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"log"
 	"math"
 	"os"
-	"os/exec"
-	"runtime"
 	"strconv"
 	"strings"
 )
@@ -21,14 +19,18 @@ const (
 	defaultMinRuntime   = 0
 	defaultMaxRuntime   = math.MaxInt
 	defaultExcludeAdult = false
+
+	defaultWeightMinVotes = 10000
+	defaultSampleStrength = 2.0
 )
 
-var defaultGenres = []string{}
+const findResultLimit = 10
 
-func GetConfigFromUser() config {
-	reader := bufio.NewReader(os.Stdin)
+var defaultGenres = []string{}
 
-	config := config{
+// DefaultConfig returns a config which matches every title with complete data
+func DefaultConfig() config {
+	return config{
 		DownloadData: false,
 		minYear:      defaultMinYear,
 		maxYear:      defaultMaxYear,
@@ -39,7 +41,39 @@ func GetConfigFromUser() config {
 		maxRuntime:   defaultMaxRuntime,
 		excludeAdult: defaultExcludeAdult,
 		genres:       defaultGenres,
+		weights:      ratingWeights{minVotes: defaultWeightMinVotes},
+
+		sampleStrength: defaultSampleStrength,
+	}
+}
+
+// GetConfig asks the user for a config, unless a query or saved preset is given
+// in which case the preset's settings, or defaults, are used with the query
+func GetConfig(source string, preset map[string]string) (config, error) {
+	if source == "" && preset == nil {
+		return GetConfigFromUser(), nil
+	}
+
+	config, err := ConfigFromSettings(preset)
+	if err != nil {
+		return config, err
+	}
+	if source == "" {
+		return config, nil
+	}
+
+	query, err := ParseQuery(source)
+	if err != nil {
+		return config, err
 	}
+	config.query = query
+	return config, nil
+}
+
+func GetConfigFromUser() config {
+	reader := bufio.NewReader(os.Stdin)
+
+	config := DefaultConfig()
 
 	fmt.Println("Download fresh dataset from IMDB? y=yes:")
 	downloadData := strings.ToLower(readLine(reader))
@@ -68,13 +102,14 @@ func GetConfigFromUser() config {
 	})
 
 	fmt.Print("Enter minimum rating: ")
-	if rating := readLine(reader); rating != "" {
-		if r, err := strconv.ParseFloat(rating, 64); err == nil {
-			config.minRating = r
-		} else {
-			log.Println("Invalid value provided, ignoring input")
-		}
-	}
+	setConfigFloat(reader, func(f float64) {
+		config.minRating = f
+	})
+
+	fmt.Print("Enter minimum weighted rating: ")
+	setConfigFloat(reader, func(f float64) {
+		config.minWeightedRating = f
+	})
 
 	fmt.Print("Enter minimum votes: ")
 	setConfigInt(reader, func(i int) {
@@ -94,71 +129,172 @@ func GetConfigFromUser() config {
 		}
 	}
 
-	return config
-}
+	fmt.Print("Titles with unknown year (exclude, include, only): ")
+	setConfigMissingPolicy(reader, func(p missingPolicy) {
+		config.missingYear = p
+	})
+
+	fmt.Print("Titles with unknown run time (exclude, include, only): ")
+	setConfigMissingPolicy(reader, func(p missingPolicy) {
+		config.missingRuntime = p
+	})
 
-func OpenMoviesInBrowser(imdbTitleUrl string, results []Movie) {
-	scanner := bufio.NewScanner(os.Stdin)
-	for len(results) > 0 {
-		movie := results[0]
+	fmt.Print("Titles without a rating (exclude, include, only): ")
+	setConfigMissingPolicy(reader, func(p missingPolicy) {
+		config.missingRating = p
+	})
 
-		fmt.Print("Press Enter to open in browser (or 'q' to quit): ")
+	fmt.Print("Enter title search (leave blank to skip): ")
+	if query := readLine(reader); query != "" {
+		fmt.Print("Enter title match mode (substring, word, regex): ")
+		mode := readLine(reader)
 
-		if !scanner.Scan() {
-			break
+		fmt.Print("Ignore accents in titles? y=yes: ")
+		ignoreAccents := strings.ToLower(readLine(reader))
+
+		title, err := newTitleSearch(query, mode, ignoreAccents == "y" || ignoreAccents == "yes")
+		if err != nil {
+			log.Println("Invalid title search, ignoring input:", err)
+		} else {
+			config.title = title
 		}
+	}
 
-		input := strings.TrimSpace(scanner.Text())
-		if strings.ToLower(input) == "q" {
-			log.Println("Quitting")
+	for {
+		fmt.Print("Enter query (leave blank to skip): ")
+		input := readLine(reader)
+		if input == "" {
 			break
 		}
+		query, err := ParseQuery(input)
+		if err == nil {
+			config.query = query
+			break
+		}
+		fmt.Println(err)
+	}
+
+	fmt.Print("Sort results by (random, sample, rating, votes, weighted, year): ")
+	if sortBy := readLine(reader); sortBy != "" {
+		if key, err := parseSortKey(sortBy); err == nil {
+			config.sortBy = key
+		} else {
+			log.Println("Invalid value provided, ignoring input")
+		}
+	}
 
-		url := fmt.Sprintf("%s/%s/", imdbTitleUrl, movie.Id)
-		if output, err := openBrowser(url); err != nil {
-			log.Fatalln("Error opening browser", err, string(output))
+	if config.sortBy == sortSample {
+		fmt.Print("Bias random sample by (rating, votes, weighted, recency): ")
+		if sampleBy := readLine(reader); sampleBy != "" {
+			if weight, err := parseSampleWeight(sampleBy); err == nil {
+				config.sampleBy = weight
+			} else {
+				log.Println("Invalid value provided, ignoring input")
+			}
 		}
 
-		results = results[1:]
+		fmt.Printf("Enter sample bias strength (default %.0f, 0 for uniform): ", defaultSampleStrength)
+		if input := readLine(reader); input != "" {
+			if strength, err := parseSampleStrength(input); err == nil {
+				config.sampleStrength = strength
+			} else {
+				log.Println("Invalid value provided, ignoring input")
+			}
+		}
 	}
 
-	if len(results) == 0 {
-		log.Println("No more results")
-		os.Exit(0)
+	if config.minWeightedRating > 0 || config.sortBy == sortWeightedRating || config.sampleBy == sampleByWeightedRating {
+		fmt.Printf("Enter weighted rating minimum votes (default %d): ", defaultWeightMinVotes)
+		setConfigInt(reader, func(i int) {
+			config.weights.minVotes = i
+		})
+
+		fmt.Print("Enter weighted rating prior mean (leave blank for dataset average): ")
+		setConfigFloat(reader, func(f float64) {
+			config.weights.priorMean = f
+		})
 	}
-}
 
-func openBrowser(url string) ([]byte, error) {
-	var cmd string
-	var args []string
-
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = "open"
-		args = []string{url}
-	case "linux":
-		if isWSL() {
-			cmd = "wslview"
-		} else {
-			cmd = "xdg-open"
+	// Settings most searches leave alone are asked for only when wanted
+	fmt.Print("More options? y=yes: ")
+	if more := strings.ToLower(readLine(reader)); more == "y" || more == "yes" {
+		fmt.Print("Include titles you have watched or dismissed? y=yes: ")
+		if includeSeen := strings.ToLower(readLine(reader)); includeSeen == "y" || includeSeen == "yes" {
+			config.includeSeen = true
+		}
+
+		fmt.Print("Only search titles on your watchlist? y=yes: ")
+		if watchlistOnly := strings.ToLower(readLine(reader)); watchlistOnly == "y" || watchlistOnly == "yes" {
+			config.WatchlistOnly = true
+		}
+
+		fmt.Print("Show a summary of the results before browsing? y=yes: ")
+		if showSummary := strings.ToLower(readLine(reader)); showSummary == "y" || showSummary == "yes" {
+			config.ShowSummary = true
 		}
-		args = []string{url}
-	case "windows":
-		cmd = "rundll32"
-		args = []string{"url.dll,FileProtocolHandler", url}
-	default:
-		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
-	return exec.Command(cmd, args...).CombinedOutput()
+	return config
 }
 
-func isWSL() bool {
-	data, err := os.ReadFile("/proc/version")
-	if err != nil {
-		return false
+// FindTitle shows fuzzy title matches for query and lets the user inspect or open them
+// with cfg's opener
+func FindTitle(imdbTitleUrl string, index *TitleIndex, ratings map[string]Rating, query string, cfg config) {
+	weights := cfg.weights.resolve(ratings)
+	reader := bufio.NewReader(os.Stdin)
+	printOnly := printLinks(os.Stdout, cfg)
+	destinations, current := cfg.destinationList(imdbTitleUrl)
+	destination := destinations[current]
+
+	if query == "" {
+		fmt.Print("Enter title to find: ")
+		query = readLine(reader)
+	}
+
+	matches := index.Search(query, findResultLimit)
+	if len(matches) == 0 {
+		log.Println("No titles found matching", query)
+		return
+	}
+
+	for {
+		fmt.Println()
+		for i, match := range matches {
+			fmt.Printf("%2d) %s (%s) - %.0f%% match\n", i+1, match.Title,
+				formatOptionalInt(match.Movie.StartYear), match.Score*100)
+		}
+
+		fmt.Print("Choose a number to see details (or 'q' to quit): ")
+		input := readLine(reader)
+		if input == "" || strings.ToLower(input) == "q" {
+			return
+		}
+
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(matches) {
+			log.Println("Invalid choice, ignoring input")
+			continue
+		}
+
+		movie := matches[choice-1].Movie
+		rating, hasRating := ratings[movie.Id]
+		fmt.Println()
+		fmt.Println(formatDetails(movie, rating, hasRating, weights))
+
+		url := destination.URL(movie)
+		if printOnly {
+			fmt.Println("  Link:", url)
+			continue
+		}
+
+		fmt.Printf("Open on %s? y=yes: ", destination.Name)
+		if open := strings.ToLower(readLine(reader)); open == "y" || open == "yes" {
+			if err := cfg.linkOpener().Open(url); err != nil {
+				log.Println("Error opening browser", err)
+				fmt.Println("  Link:", url)
+			}
+		}
 	}
-	return strings.Contains(strings.ToLower(string(data)), "microsoft")
 }
 
 func setConfigInt(reader *bufio.Reader, configFunc func(int)) {
@@ -171,6 +307,26 @@ func setConfigInt(reader *bufio.Reader, configFunc func(int)) {
 	}
 }
 
+func setConfigFloat(reader *bufio.Reader, configFunc func(float64)) {
+	if input := readLine(reader); input != "" {
+		if v, err := strconv.ParseFloat(input, 64); err == nil {
+			configFunc(v)
+			return
+		}
+		log.Println("Invalid value provided, ignoring input")
+	}
+}
+
+func setConfigMissingPolicy(reader *bufio.Reader, configFunc func(missingPolicy)) {
+	if input := readLine(reader); input != "" {
+		if p, err := parseMissingPolicy(input); err == nil {
+			configFunc(p)
+			return
+		}
+		log.Println("Invalid value provided, ignoring input")
+	}
+}
+
 func readLine(reader *bufio.Reader) string {
 	line, _ := reader.ReadString('\n')
 	return strings.TrimSpace(line)
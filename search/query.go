@@ -0,0 +1,405 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed query expression such as
+//
+//	year >= 1990 and (genre:horror or genre:thriller) and not genre:comedy and title ~ "night"
+//
+// Numeric fields are year, runtime, rating, votes and weighted and support
+// =, !=, <, <=, > and >=. The title field supports = for an exact match and ~
+// for a substring match, both ignoring case and accents. The genre, type and id
+// fields support = (or :) and !=. The bare word adult matches adult titles.
+type Query struct {
+	source string
+	root   queryNode
+}
+
+// QueryError describes a syntax error and the column it was found at
+type QueryError struct {
+	Query  string
+	Column int // 1-based
+	Msg    string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s\n  %s\n  %s^",
+		e.Column, e.Msg, e.Query, strings.Repeat(" ", e.Column-1))
+}
+
+func ParseQuery(source string) (*Query, error) {
+	tokens, err := lexQuery(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{source: source, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorAt(tok, "unexpected %s", tok.describe())
+	}
+
+	return &Query{source: source, root: root}, nil
+}
+
+func (q *Query) String() string {
+	return q.source
+}
+
+func (q *Query) matches(movie Movie, rating Rating, hasRating bool, weights ratingWeights) bool {
+	return q.root.eval(queryTarget{movie, rating, hasRating, weights})
+}
+
+type queryTarget struct {
+	movie     Movie
+	rating    Rating
+	hasRating bool
+	weights   ratingWeights
+}
+
+type queryNode interface {
+	eval(t queryTarget) bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ operand queryNode }
+type adultNode struct{}
+
+type numberNode struct {
+	field string
+	op    string
+	value float64
+}
+
+type textNode struct {
+	field string
+	op    string
+	value string
+}
+
+func (n andNode) eval(t queryTarget) bool { return n.left.eval(t) && n.right.eval(t) }
+func (n orNode) eval(t queryTarget) bool  { return n.left.eval(t) || n.right.eval(t) }
+func (n notNode) eval(t queryTarget) bool { return !n.operand.eval(t) }
+func (adultNode) eval(t queryTarget) bool { return t.movie.isAdult }
+
+func (n numberNode) eval(t queryTarget) bool {
+	var value float64
+	switch n.field {
+	case "year":
+		if t.movie.StartYear == nil {
+			return false
+		}
+		value = float64(*t.movie.StartYear)
+	case "runtime":
+		if t.movie.runtimeMinutes == nil {
+			return false
+		}
+		value = float64(*t.movie.runtimeMinutes)
+	case "rating":
+		if !t.hasRating {
+			return false
+		}
+		value = t.rating.AverageRating
+	case "votes":
+		if !t.hasRating {
+			return false
+		}
+		value = float64(t.rating.NumVotes)
+	case "weighted":
+		if !t.hasRating {
+			return false
+		}
+		value = t.weights.weightedRating(t.rating)
+	}
+
+	switch n.op {
+	case "=":
+		return value == n.value
+	case "!=":
+		return value != n.value
+	case "<":
+		return value < n.value
+	case "<=":
+		return value <= n.value
+	case ">":
+		return value > n.value
+	default:
+		return value >= n.value
+	}
+}
+
+func (n textNode) eval(t queryTarget) bool {
+	var matched bool
+	switch n.field {
+	case "genre":
+		matched = slices.ContainsFunc(t.movie.Genres, func(g string) bool {
+			return strings.EqualFold(g, n.value)
+		})
+	case "type":
+		matched = strings.EqualFold(t.movie.titleType, n.value)
+	case "id":
+		matched = t.movie.Id == n.value
+	case "title":
+		titles := append([]string{t.movie.PrimaryTitle, t.movie.originalTitle}, t.movie.akas...)
+		matched = slices.ContainsFunc(titles, func(title string) bool {
+			title = normalizeTitle(title, true)
+			if n.op == "~" {
+				return strings.Contains(title, n.value)
+			}
+			return title == n.value
+		})
+	}
+
+	if n.op == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+var (
+	numberFields = []string{"year", "runtime", "rating", "votes", "weighted"}
+	textFields   = []string{"title", "genre", "type", "id"}
+)
+
+type queryParser struct {
+	source string
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) errorAt(tok queryToken, format string, args ...any) error {
+	return &QueryError{Query: p.source, Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.peek().isKeyword("not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+
+	switch {
+	case tok.kind == tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorAt(closing, "expected ')' to close '(' at column %d, found %s", tok.column, closing.describe())
+		}
+		return node, nil
+	case tok.kind == tokenWord:
+		return p.parseComparison(tok)
+	default:
+		return nil, p.errorAt(tok, "expected a field, 'not' or '(', found %s", tok.describe())
+	}
+}
+
+func (p *queryParser) parseComparison(field queryToken) (queryNode, error) {
+	name := strings.ToLower(field.text)
+	if name == "adult" {
+		return adultNode{}, nil
+	}
+
+	isNumber := slices.Contains(numberFields, name)
+	if !isNumber && !slices.Contains(textFields, name) {
+		return nil, p.errorAt(field, "unknown field %q, expected one of adult, %s, %s",
+			field.text, strings.Join(numberFields, ", "), strings.Join(textFields, ", "))
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokenOperator {
+		return nil, p.errorAt(opTok, "expected an operator after %q, found %s", field.text, opTok.describe())
+	}
+	op := opTok.text
+	if op == ":" {
+		op = "="
+	}
+
+	valueTok := p.next()
+	if valueTok.kind != tokenWord && valueTok.kind != tokenString {
+		return nil, p.errorAt(valueTok, "expected a value after %q, found %s", opTok.text, valueTok.describe())
+	}
+
+	if isNumber {
+		if op == "~" {
+			return nil, p.errorAt(opTok, "operator '~' cannot be used with numeric field %q", field.text)
+		}
+		value, err := strconv.ParseFloat(valueTok.text, 64)
+		if err != nil || valueTok.kind == tokenString {
+			return nil, p.errorAt(valueTok, "expected a number for %q, found %s", field.text, valueTok.describe())
+		}
+		return numberNode{field: name, op: op, value: value}, nil
+	}
+
+	switch {
+	case op == "~" && name != "title":
+		return nil, p.errorAt(opTok, "operator '~' can only be used with title")
+	case op != "=" && op != "!=" && op != "~":
+		return nil, p.errorAt(opTok, "operator %q cannot be used with text field %q", opTok.text, field.text)
+	}
+
+	value := valueTok.text
+	if name == "title" {
+		value = normalizeTitle(value, true)
+	}
+	return textNode{field: name, op: op, value: value}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type queryToken struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+func (t queryToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t queryToken) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+var queryOperators = []string{">=", "<=", "!=", "=", "<", ">", "~", ":"}
+
+func lexQuery(source string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenLParen, "(", column})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenRParen, ")", column})
+			i++
+		case r == '"':
+			end := i + 1
+			var b strings.Builder
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' && end+1 < len(runes) {
+					end++
+				}
+				b.WriteRune(runes[end])
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &QueryError{Query: source, Column: column, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, queryToken{tokenString, b.String(), column})
+			i = end + 1
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, queryToken{tokenWord, string(runes[i:end]), column})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range queryOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &QueryError{Query: source, Column: column, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, queryToken{tokenOperator, op, column})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, queryToken{tokenEOF, "", len(runes) + 1}), nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_'
+}
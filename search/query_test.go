@@ -0,0 +1,125 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseQuery_Matches(t *testing.T) {
+	movies, ratings := setupTestData()
+	movies["4"] = Movie{
+		Id:           "4",
+		titleType:    "tvMovie",
+		PrimaryTitle: "Comedy Show",
+		StartYear:    intPtr(2018),
+		Genres:       []string{"Comedy"},
+	}
+
+	testCases := []struct {
+		query       string
+		expectedIDs []string
+	}{
+		{"year >= 2020", []string{"1", "3", "5", "7", "8"}},
+		{"year > 2019 and year < 2021", []string{"1", "7", "8"}},
+		{"genre:action", []string{"1", "3", "5"}},
+		{"genre = Action and not genre:thriller", []string{"1", "5"}},
+		{"year >= 2019 and (genre:drama or genre:thriller) and not adult", []string{"3", "7"}},
+		{"rating >= 8.5 or votes > 15000", []string{"1", "3", "6"}},
+		{`title ~ "night"`, []string{"3"}},
+		{`title = "action hero"`, []string{"1"}},
+		{`title != "action hero" and genre:action`, []string{"3", "5"}},
+		{"runtime < 60 or runtime > 180", []string{"7", "8"}},
+		{"type:tvmovie", []string{"4"}},
+		{"adult", []string{"2"}},
+		{"id = 6", []string{"6"}},
+		{"not runtime > 0", []string{"4"}},
+		{"weighted > 8.9", []string{"3"}},
+		{"NOT genre:drama AND genre:action OR id:8", []string{"1", "3", "5", "8"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			query, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, movie := range movies {
+				rating, hasRating := ratings[movie.Id]
+				if query.matches(movie, rating, hasRating, ratingWeights{}) {
+					ids = append(ids, movie.Id)
+				}
+			}
+			slices.Sort(ids)
+
+			if !slices.Equal(ids, tc.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestParseQuery_SyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		query  string
+		column int
+	}{
+		{"year >=", 8},
+		{"year >= 1990 and", 17},
+		{"(genre:horror or genre:thriller", 32},
+		{"yeer > 1990", 1},
+		{"year > abc", 8},
+		{"rating ~ 5", 8},
+		{"genre > horror", 7},
+		{`title ~ "night`, 9},
+		{"year > 1990 genre:horror", 13},
+		{"year $ 1990", 6},
+		{"and year > 1990", 1},
+		{"year > 1990)", 12},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := ParseQuery(tc.query)
+
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("Expected a QueryError, got %v", err)
+			}
+			if queryErr.Column != tc.column {
+				t.Errorf("Expected error at column %d, got %d: %v", tc.column, queryErr.Column, err)
+			}
+		})
+	}
+}
+
+func TestFilterMovies_QueryFilter(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	query, err := ParseQuery("genre:action and votes >= 12000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg := config{
+		maxYear:    math.MaxInt,
+		maxRuntime: math.MaxInt,
+		maxVotes:   math.MaxInt,
+		query:      query,
+	}
+
+	for _, filterFunc := range []func(map[string]Movie, map[string]Rating, config) []Movie{FilterMoviesSync, FilterMovies} {
+		results := filterFunc(movies, ratings, cfg)
+		if len(results) != 2 {
+			t.Errorf("Expected 2 results, got %d", len(results))
+		}
+		for _, movie := range results {
+			if movie.Id != "3" && movie.Id != "5" {
+				t.Errorf("Movie %s should not be in results", movie.Id)
+			}
+		}
+	}
+}
@@ -1,7 +1,44 @@
 package search
 
-type rating struct {
+type Rating struct {
 	id            string
 	AverageRating float64
 	NumVotes      int
 }
+
+// ratingWeights configures an IMDB-style Bayesian average which pulls ratings with
+// few votes towards priorMean, minVotes controls how strong that pull is
+type ratingWeights struct {
+	priorMean float64 // Zero means use the dataset average
+	minVotes  int
+}
+
+func (w ratingWeights) weightedRating(r Rating) float64 {
+	votes := float64(r.NumVotes)
+	minVotes := float64(w.minVotes)
+	if votes+minVotes == 0 {
+		return w.priorMean
+	}
+
+	return votes/(votes+minVotes)*r.AverageRating + minVotes/(votes+minVotes)*w.priorMean
+}
+
+// resolve fills in the prior mean from the dataset when it was not configured
+func (w ratingWeights) resolve(ratings map[string]Rating) ratingWeights {
+	if w.priorMean == 0 {
+		w.priorMean = meanRating(ratings)
+	}
+	return w
+}
+
+func meanRating(ratings map[string]Rating) float64 {
+	if len(ratings) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, r := range ratings {
+		sum += r.AverageRating
+	}
+	return sum / float64(len(ratings))
+}
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type sortKey int
+
+const (
+	sortRandom sortKey = iota
+	sortRating
+	sortVotes
+	sortWeightedRating
+	sortYear
+	sortSample
+)
+
+type sampleWeight int
+
+const (
+	sampleByRating sampleWeight = iota
+	sampleByVotes
+	sampleByWeightedRating
+	sampleByRecency
+)
+
+func (k sortKey) String() string {
+	switch k {
+	case sortRating:
+		return "rating"
+	case sortVotes:
+		return "votes"
+	case sortWeightedRating:
+		return "weighted"
+	case sortYear:
+		return "year"
+	case sortSample:
+		return "sample"
+	default:
+		return "random"
+	}
+}
+
+func (w sampleWeight) String() string {
+	switch w {
+	case sampleByVotes:
+		return "votes"
+	case sampleByWeightedRating:
+		return "weighted"
+	case sampleByRecency:
+		return "recency"
+	default:
+		return "rating"
+	}
+}
+
+func parseSortKey(key string) (sortKey, error) {
+	switch strings.ToLower(key) {
+	case "", "random":
+		return sortRandom, nil
+	case "rating":
+		return sortRating, nil
+	case "votes":
+		return sortVotes, nil
+	case "weighted":
+		return sortWeightedRating, nil
+	case "year":
+		return sortYear, nil
+	case "sample":
+		return sortSample, nil
+	default:
+		return sortRandom, fmt.Errorf("unknown sort key: %s", key)
+	}
+}
+
+func parseSampleWeight(weight string) (sampleWeight, error) {
+	switch strings.ToLower(weight) {
+	case "", "rating":
+		return sampleByRating, nil
+	case "votes":
+		return sampleByVotes, nil
+	case "weighted":
+		return sampleByWeightedRating, nil
+	case "recency":
+		return sampleByRecency, nil
+	default:
+		return sampleByRating, fmt.Errorf("unknown sample weight: %s", weight)
+	}
+}
+
+// parseSampleStrength reads how strongly sampling favours heavier movies, an
+// empty value being the default
+func parseSampleStrength(strength string) (float64, error) {
+	if strength == "" {
+		return defaultSampleStrength, nil
+	}
+	v, err := strconv.ParseFloat(strength, 64)
+	if err != nil || math.IsNaN(v) {
+		return defaultSampleStrength, fmt.Errorf("%q is not a number", strength)
+	}
+	if v < 0 {
+		return defaultSampleStrength, fmt.Errorf("sample strength %s is negative", strength)
+	}
+	return v, nil
+}
+
+// orderResults sorts movies best first by the configured key, or shuffles them
+func orderResults(movies []Movie, ratings map[string]Rating, cfg config) {
+	switch cfg.sortBy {
+	case sortRandom:
+		randomizeResults(movies)
+		return
+	case sortSample:
+		sampleResults(movies, ratings, cfg)
+		return
+	}
+
+	slices.SortFunc(movies, func(a, b Movie) int {
+		if c := cmp.Compare(sortValue(b, ratings, cfg), sortValue(a, ratings, cfg)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+}
+
+func sortValue(movie Movie, ratings map[string]Rating, cfg config) float64 {
+	rating, hasRating := ratings[movie.Id]
+
+	switch cfg.sortBy {
+	case sortYear:
+		if movie.StartYear == nil {
+			return 0
+		}
+		return float64(*movie.StartYear)
+	case sortVotes:
+		return float64(rating.NumVotes)
+	case sortWeightedRating:
+		if !hasRating {
+			return 0
+		}
+		return cfg.weights.weightedRating(rating)
+	default:
+		return rating.AverageRating
+	}
+}
+
+// sampleResults shuffles movies so that heavier movies tend to come first, using
+// weighted sampling without replacement (Efraimidis-Spirakis) where each movie gets
+// the key log(u)/weight for a uniform random u. The keys are compared in log
+// space, as log(-log(u)) - strength*log(base), so that a large strength cannot
+// overflow the weight.
+func sampleResults(movies []Movie, ratings map[string]Rating, cfg config) {
+	earliestYear := math.MaxInt
+	for _, movie := range movies {
+		if movie.StartYear != nil {
+			earliestYear = min(earliestYear, *movie.StartYear)
+		}
+	}
+
+	keys := make(map[string]float64, len(movies))
+	for _, movie := range movies {
+		logWeight := cfg.sampleStrength * math.Log(sampleWeightOf(movie, ratings, cfg, earliestYear))
+		keys[movie.Id] = math.Log(-math.Log(1-rand.Float64())) - logWeight
+	}
+
+	slices.SortFunc(movies, func(a, b Movie) int {
+		return cmp.Compare(keys[a.Id], keys[b.Id])
+	})
+}
+
+// sampleWeightOf is always at least 1 so that every movie keeps a chance of being picked
+func sampleWeightOf(movie Movie, ratings map[string]Rating, cfg config, earliestYear int) float64 {
+	rating, hasRating := ratings[movie.Id]
+
+	switch cfg.sampleBy {
+	case sampleByVotes:
+		return 1 + math.Log1p(float64(rating.NumVotes))
+	case sampleByWeightedRating:
+		if !hasRating {
+			return 1
+		}
+		return max(1, cfg.weights.weightedRating(rating))
+	case sampleByRecency:
+		if movie.StartYear == nil {
+			return 1
+		}
+		return float64(1 + *movie.StartYear - earliestYear)
+	default:
+		return max(1, rating.AverageRating)
+	}
+}
@@ -0,0 +1,229 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const topGenresPerDecade = 5
+
+// DatasetStats describes a whole loaded dataset rather than a single search
+type DatasetStats struct {
+	Titles          int                    `json:"titles"`
+	TitlesPerYear   map[int]int            `json:"titlesPerYear"`
+	GenresByDecade  map[int]map[string]int `json:"genresByDecade"` // Keyed by the first year of the decade
+	GenreRatings    []GenreRating          `json:"genreRatings"`
+	MostVotedByYear map[int]VotedTitle     `json:"mostVotedByYear"`
+
+	// RuntimeRatingCorrelation is the Pearson correlation between run time and
+	// rating over titles which have both
+	RuntimeRatingCorrelation float64 `json:"runtimeRatingCorrelation"`
+	CorrelationSamples       int     `json:"correlationSamples"`
+}
+
+type GenreRating struct {
+	Genre         string  `json:"genre"`
+	RatedTitles   int     `json:"ratedTitles"`
+	AverageRating float64 `json:"averageRating"`
+}
+
+type VotedTitle struct {
+	Id     string  `json:"id"`
+	Title  string  `json:"title"`
+	Votes  int     `json:"votes"`
+	Rating float64 `json:"rating"`
+}
+
+// statsAccumulator holds running totals for one chunk of the dataset
+type statsAccumulator struct {
+	stats       *DatasetStats
+	genreSums   map[string]float64
+	genreCounts map[string]int
+
+	n, sumX, sumY, sumXX, sumYY, sumXY float64
+}
+
+// ComputeDatasetStats aggregates the whole dataset, splitting the work between
+// the same number of workers as FilterMovies
+func ComputeDatasetStats(movies map[string]Movie, ratings map[string]Rating) *DatasetStats {
+	chunks := partitionSlice(mapToSlice(movies), numWorkers())
+	partials := make([]*statsAccumulator, len(chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, movies []Movie) {
+			defer wg.Done()
+			partials[i] = accumulateStats(movies, ratings)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	total := newStatsAccumulator()
+	for _, partial := range partials {
+		total.merge(partial)
+	}
+	return total.finish()
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{
+		stats: &DatasetStats{
+			TitlesPerYear:   make(map[int]int),
+			GenresByDecade:  make(map[int]map[string]int),
+			MostVotedByYear: make(map[int]VotedTitle),
+		},
+		genreSums:   make(map[string]float64),
+		genreCounts: make(map[string]int),
+	}
+}
+
+func accumulateStats(movies []Movie, ratings map[string]Rating) *statsAccumulator {
+	acc := newStatsAccumulator()
+	stats := acc.stats
+
+	for _, movie := range movies {
+		stats.Titles++
+		rating, hasRating := ratings[movie.Id]
+
+		if movie.StartYear != nil {
+			year := *movie.StartYear
+			stats.TitlesPerYear[year]++
+
+			decade := year / 10 * 10
+			if stats.GenresByDecade[decade] == nil {
+				stats.GenresByDecade[decade] = make(map[string]int)
+			}
+			for _, genre := range movie.Genres {
+				stats.GenresByDecade[decade][genre]++
+			}
+
+			if hasRating && rating.NumVotes > stats.MostVotedByYear[year].Votes {
+				stats.MostVotedByYear[year] = VotedTitle{
+					Id:     movie.Id,
+					Title:  movie.PrimaryTitle,
+					Votes:  rating.NumVotes,
+					Rating: rating.AverageRating,
+				}
+			}
+		}
+
+		if !hasRating {
+			continue
+		}
+
+		for _, genre := range movie.Genres {
+			acc.genreSums[genre] += rating.AverageRating
+			acc.genreCounts[genre]++
+		}
+
+		if movie.runtimeMinutes != nil {
+			x, y := float64(*movie.runtimeMinutes), rating.AverageRating
+			acc.n++
+			acc.sumX += x
+			acc.sumY += y
+			acc.sumXX += x * x
+			acc.sumYY += y * y
+			acc.sumXY += x * y
+		}
+	}
+
+	return acc
+}
+
+func (acc *statsAccumulator) merge(other *statsAccumulator) {
+	acc.stats.Titles += other.stats.Titles
+	mergeCounts(acc.stats.TitlesPerYear, other.stats.TitlesPerYear)
+
+	for decade, genres := range other.stats.GenresByDecade {
+		if acc.stats.GenresByDecade[decade] == nil {
+			acc.stats.GenresByDecade[decade] = make(map[string]int)
+		}
+		mergeCounts(acc.stats.GenresByDecade[decade], genres)
+	}
+
+	for year, title := range other.stats.MostVotedByYear {
+		if title.Votes > acc.stats.MostVotedByYear[year].Votes {
+			acc.stats.MostVotedByYear[year] = title
+		}
+	}
+
+	for genre, sum := range other.genreSums {
+		acc.genreSums[genre] += sum
+	}
+	mergeCounts(acc.genreCounts, other.genreCounts)
+
+	acc.n += other.n
+	acc.sumX += other.sumX
+	acc.sumY += other.sumY
+	acc.sumXX += other.sumXX
+	acc.sumYY += other.sumYY
+	acc.sumXY += other.sumXY
+}
+
+func (acc *statsAccumulator) finish() *DatasetStats {
+	stats := acc.stats
+
+	for genre, count := range acc.genreCounts {
+		stats.GenreRatings = append(stats.GenreRatings, GenreRating{
+			Genre:         genre,
+			RatedTitles:   count,
+			AverageRating: acc.genreSums[genre] / float64(count),
+		})
+	}
+	slices.SortFunc(stats.GenreRatings, func(a, b GenreRating) int {
+		if c := cmp.Compare(b.AverageRating, a.AverageRating); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Genre, b.Genre)
+	})
+
+	stats.CorrelationSamples = int(acc.n)
+	covariance := acc.n*acc.sumXY - acc.sumX*acc.sumY
+	variance := math.Sqrt(acc.n*acc.sumXX-acc.sumX*acc.sumX) * math.Sqrt(acc.n*acc.sumYY-acc.sumY*acc.sumY)
+	if variance > 0 {
+		stats.RuntimeRatingCorrelation = covariance / variance
+	}
+
+	return stats
+}
+
+// Print writes the statistics as text tables
+func (s *DatasetStats) Print(w io.Writer) {
+	fmt.Fprintf(w, "\nDataset of %d titles\n", s.Titles)
+
+	fmt.Fprintln(w, "\nTitles per year:")
+	for _, year := range slices.Sorted(maps.Keys(s.TitlesPerYear)) {
+		fmt.Fprintf(w, "  %-6d %d\n", year, s.TitlesPerYear[year])
+	}
+
+	fmt.Fprintf(w, "\nMost popular genres per decade:\n")
+	for _, decade := range slices.Sorted(maps.Keys(s.GenresByDecade)) {
+		genres := keysByCount(s.GenresByDecade[decade])
+		parts := make([]string, 0, topGenresPerDecade)
+		for _, genre := range genres[:min(len(genres), topGenresPerDecade)] {
+			parts = append(parts, fmt.Sprintf("%s %d", genre, s.GenresByDecade[decade][genre]))
+		}
+		fmt.Fprintf(w, "  %-6s %s\n", fmt.Sprintf("%ds", decade), strings.Join(parts, ", "))
+	}
+
+	fmt.Fprintln(w, "\nAverage rating by genre:")
+	for _, genre := range s.GenreRatings {
+		fmt.Fprintf(w, "  %-12s %.2f (%d rated titles)\n", genre.Genre, genre.AverageRating, genre.RatedTitles)
+	}
+
+	fmt.Fprintln(w, "\nMost voted title per year:")
+	for _, year := range slices.Sorted(maps.Keys(s.MostVotedByYear)) {
+		title := s.MostVotedByYear[year]
+		fmt.Fprintf(w, "  %-6d %s (%s) - %.1f, %d votes\n", year, title.Title, title.Id, title.Rating, title.Votes)
+	}
+
+	fmt.Fprintf(w, "\nRun time and rating correlation: %.3f over %d titles\n",
+		s.RuntimeRatingCorrelation, s.CorrelationSamples)
+}
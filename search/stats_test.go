@@ -0,0 +1,45 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestComputeDatasetStats(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	for _, workers := range []string{"1", "3", "16"} {
+		t.Setenv(searchWorkersEnv, workers)
+		stats := ComputeDatasetStats(movies, ratings)
+
+		if stats.Titles != 8 {
+			t.Errorf("Expected 8 titles, got %d", stats.Titles)
+		}
+		if expected := map[int]int{2005: 1, 2018: 1, 2019: 1, 2020: 3, 2021: 1, 2022: 1}; !reflect.DeepEqual(stats.TitlesPerYear, expected) {
+			t.Errorf("Expected titles per year %v, got %v", expected, stats.TitlesPerYear)
+		}
+		if expected := map[string]int{"Action": 3, "Drama": 1, "Thriller": 1, "Documentary": 1}; !reflect.DeepEqual(stats.GenresByDecade[2020], expected) {
+			t.Errorf("Expected 2020s genres %v, got %v", expected, stats.GenresByDecade[2020])
+		}
+		if mostVoted := stats.MostVotedByYear[2020]; mostVoted.Id != "1" || mostVoted.Votes != 10000 {
+			t.Errorf("Expected 1 to be the most voted title of 2020, got %+v", mostVoted)
+		}
+
+		if top := stats.GenreRatings[0]; top.Genre != "Thriller" || top.AverageRating != 9.0 {
+			t.Errorf("Expected Thriller to have the highest average rating, got %+v", top)
+		}
+		for _, genre := range stats.GenreRatings {
+			if genre.Genre == "Action" && math.Abs(genre.AverageRating-(8.5+9.0+8.0)/3) > 1e-9 {
+				t.Errorf("Expected Action average rating %.3f, got %.3f", (8.5+9.0+8.0)/3, genre.AverageRating)
+			}
+		}
+
+		if stats.CorrelationSamples != 8 {
+			t.Errorf("Expected 8 correlation samples, got %d", stats.CorrelationSamples)
+		}
+		if stats.RuntimeRatingCorrelation <= 0 || stats.RuntimeRatingCorrelation > 1 {
+			t.Errorf("Expected a positive correlation between run time and rating, got %v", stats.RuntimeRatingCorrelation)
+		}
+	}
+}
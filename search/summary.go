@@ -0,0 +1,207 @@
+package search
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	runtimeBucketMinutes = 30
+	maxRuntimeBucket     = 180
+)
+
+var summaryQuantiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// Summary holds facet counts and distributions over a set of results
+type Summary struct {
+	Total            int            `json:"total"`
+	Genres           map[string]int `json:"genres"`
+	Decades          map[int]int    `json:"decades"`    // Keyed by the first year of the decade
+	TitleTypes       map[string]int `json:"titleTypes"` // Keyed by IMDB title type
+	RatingHistogram  [10]int        `json:"ratingHistogram"`
+	RuntimeBuckets   map[int]int    `json:"runtimeBuckets"` // Keyed by the bucket's lowest runtime
+	VoteQuantiles    []Quantile     `json:"voteQuantiles"`
+	RuntimeQuantiles []Quantile     `json:"runtimeQuantiles"`
+	UnknownYear      int            `json:"unknownYear"`
+	UnknownRuntime   int            `json:"unknownRuntime"`
+	Unrated          int            `json:"unrated"`
+
+	votes    []int
+	runtimes []int
+}
+
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    int     `json:"value"`
+}
+
+func newSummary() *Summary {
+	return &Summary{
+		Genres:         make(map[string]int),
+		Decades:        make(map[int]int),
+		TitleTypes:     make(map[string]int),
+		RuntimeBuckets: make(map[int]int),
+	}
+}
+
+// Summarize computes facet counts for results
+func Summarize(results []Movie, ratings map[string]Rating) *Summary {
+	summary := summarizeSlice(results, ratings)
+	summary.finish()
+	return summary
+}
+
+// summarizeSlice computes a partial summary which can be merged with others before finishing
+func summarizeSlice(movies []Movie, ratings map[string]Rating) *Summary {
+	summary := newSummary()
+
+	for _, movie := range movies {
+		summary.Total++
+		summary.TitleTypes[movie.titleType]++
+		for _, genre := range movie.Genres {
+			summary.Genres[genre]++
+		}
+
+		if movie.StartYear == nil {
+			summary.UnknownYear++
+		} else {
+			summary.Decades[*movie.StartYear/10*10]++
+		}
+
+		if movie.runtimeMinutes == nil {
+			summary.UnknownRuntime++
+		} else {
+			runtime := *movie.runtimeMinutes
+			summary.RuntimeBuckets[min(runtime/runtimeBucketMinutes*runtimeBucketMinutes, maxRuntimeBucket)]++
+			summary.runtimes = append(summary.runtimes, runtime)
+		}
+
+		if rating, hasRating := ratings[movie.Id]; hasRating {
+			bucket := min(int(rating.AverageRating), len(summary.RatingHistogram)-1)
+			summary.RatingHistogram[max(bucket, 0)]++
+			summary.votes = append(summary.votes, rating.NumVotes)
+		} else {
+			summary.Unrated++
+		}
+	}
+
+	return summary
+}
+
+func (s *Summary) merge(other *Summary) {
+	s.Total += other.Total
+	s.UnknownYear += other.UnknownYear
+	s.UnknownRuntime += other.UnknownRuntime
+	s.Unrated += other.Unrated
+	mergeCounts(s.Genres, other.Genres)
+	mergeCounts(s.Decades, other.Decades)
+	mergeCounts(s.TitleTypes, other.TitleTypes)
+	mergeCounts(s.RuntimeBuckets, other.RuntimeBuckets)
+	for i, count := range other.RatingHistogram {
+		s.RatingHistogram[i] += count
+	}
+	s.votes = append(s.votes, other.votes...)
+	s.runtimes = append(s.runtimes, other.runtimes...)
+}
+
+// finish computes quantiles once every partial summary has been merged
+func (s *Summary) finish() {
+	s.VoteQuantiles = quantiles(s.votes)
+	s.RuntimeQuantiles = quantiles(s.runtimes)
+	s.votes, s.runtimes = nil, nil
+}
+
+func mergeCounts[K comparable](into, from map[K]int) {
+	for key, count := range from {
+		into[key] += count
+	}
+}
+
+// quantiles uses the nearest-rank method
+func quantiles(values []int) []Quantile {
+	if len(values) == 0 {
+		return nil
+	}
+	slices.Sort(values)
+
+	result := make([]Quantile, 0, len(summaryQuantiles))
+	for _, q := range summaryQuantiles {
+		rank := int(math.Ceil(q*float64(len(values)))) - 1
+		result = append(result, Quantile{Quantile: q, Value: values[max(rank, 0)]})
+	}
+	return result
+}
+
+// Print writes the summary as text tables
+func (s *Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "\nSummary of %d results\n", s.Total)
+
+	fmt.Fprintln(w, "\nGenres:")
+	for _, genre := range keysByCount(s.Genres) {
+		fmt.Fprintf(w, "  %-12s %d\n", genre, s.Genres[genre])
+	}
+
+	fmt.Fprintln(w, "\nTitle types:")
+	for _, titleType := range keysByCount(s.TitleTypes) {
+		fmt.Fprintf(w, "  %-12s %d\n", titleType, s.TitleTypes[titleType])
+	}
+
+	fmt.Fprintln(w, "\nDecades:")
+	for _, decade := range slices.Sorted(maps.Keys(s.Decades)) {
+		fmt.Fprintf(w, "  %-12s %d\n", fmt.Sprintf("%ds", decade), s.Decades[decade])
+	}
+	if s.UnknownYear > 0 {
+		fmt.Fprintf(w, "  %-12s %d\n", "unknown", s.UnknownYear)
+	}
+
+	fmt.Fprintln(w, "\nRatings:")
+	for bucket, count := range s.RatingHistogram {
+		fmt.Fprintf(w, "  %-12s %d\n", fmt.Sprintf("%d-%d", bucket, bucket+1), count)
+	}
+	if s.Unrated > 0 {
+		fmt.Fprintf(w, "  %-12s %d\n", "unrated", s.Unrated)
+	}
+
+	fmt.Fprintln(w, "\nRun times:")
+	for _, bucket := range slices.Sorted(maps.Keys(s.RuntimeBuckets)) {
+		label := fmt.Sprintf("%d-%d min", bucket, bucket+runtimeBucketMinutes-1)
+		if bucket == maxRuntimeBucket {
+			label = fmt.Sprintf("%d+ min", bucket)
+		}
+		fmt.Fprintf(w, "  %-12s %d\n", label, s.RuntimeBuckets[bucket])
+	}
+	if s.UnknownRuntime > 0 {
+		fmt.Fprintf(w, "  %-12s %d\n", "unknown", s.UnknownRuntime)
+	}
+
+	fmt.Fprintf(w, "\nVote quantiles:     %s\n", formatQuantiles(s.VoteQuantiles))
+	fmt.Fprintf(w, "Run time quantiles: %s\n", formatQuantiles(s.RuntimeQuantiles))
+}
+
+func formatQuantiles(quantiles []Quantile) string {
+	if len(quantiles) == 0 {
+		return "none"
+	}
+
+	parts := make([]string, 0, len(quantiles))
+	for _, q := range quantiles {
+		parts = append(parts, fmt.Sprintf("p%.0f=%d", q.Quantile*100, q.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func keysByCount(counts map[string]int) []string {
+	keys := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
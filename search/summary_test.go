@@ -0,0 +1,87 @@
+package search
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterMoviesWithSummary(t *testing.T) {
+	movies, ratings := setupTestData()
+	movies["4"] = Movie{Id: "4", titleType: "tvMovie", PrimaryTitle: "Comedy Show", StartYear: intPtr(2018), Genres: []string{"Comedy"}}
+	delete(ratings, "8")
+
+	cfg := config{
+		maxYear:        math.MaxInt,
+		maxRuntime:     math.MaxInt,
+		maxVotes:       math.MaxInt,
+		missingRuntime: missingInclude,
+		missingRating:  missingInclude,
+	}
+	for _, movie := range movies {
+		if movie.titleType == "" {
+			movie.titleType = "movie"
+			movies[movie.Id] = movie
+		}
+	}
+
+	for _, workers := range []string{"1", "3", "16"} {
+		t.Setenv(searchWorkersEnv, workers)
+
+		results, summary := FilterMoviesWithSummary(movies, ratings, cfg)
+		if len(results) != 8 || summary.Total != 8 {
+			t.Fatalf("Expected 8 results in summary, got %d results and total %d", len(results), summary.Total)
+		}
+
+		expectedGenres := map[string]int{"Action": 3, "Drama": 3, "Thriller": 1, "Comedy": 1, "Documentary": 1}
+		if !reflect.DeepEqual(summary.Genres, expectedGenres) {
+			t.Errorf("Expected genres %v, got %v", expectedGenres, summary.Genres)
+		}
+		if expected := map[int]int{2000: 1, 2010: 2, 2020: 5}; !reflect.DeepEqual(summary.Decades, expected) {
+			t.Errorf("Expected decades %v, got %v", expected, summary.Decades)
+		}
+		if expected := map[string]int{"movie": 7, "tvMovie": 1}; !reflect.DeepEqual(summary.TitleTypes, expected) {
+			t.Errorf("Expected title types %v, got %v", expected, summary.TitleTypes)
+		}
+		if expected := [10]int{6: 1, 7: 2, 8: 3, 9: 1}; summary.RatingHistogram != expected {
+			t.Errorf("Expected rating histogram %v, got %v", expected, summary.RatingHistogram)
+		}
+		if summary.Unrated != 1 || summary.UnknownRuntime != 1 {
+			t.Errorf("Expected 1 unrated and 1 unknown runtime, got %d and %d", summary.Unrated, summary.UnknownRuntime)
+		}
+		if expected := map[int]int{30: 1, 90: 2, 120: 2, 150: 1, 180: 1}; !reflect.DeepEqual(summary.RuntimeBuckets, expected) {
+			t.Errorf("Expected runtime buckets %v, got %v", expected, summary.RuntimeBuckets)
+		}
+
+		if !reflect.DeepEqual(summary, Summarize(FilterMoviesSync(movies, ratings, cfg), ratings)) {
+			t.Errorf("Parallel summary with %s workers differs from sequential summary", workers)
+		}
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	values := []int{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	expected := []Quantile{{0.1, 1}, {0.25, 3}, {0.5, 5}, {0.75, 8}, {0.9, 9}}
+
+	if got := quantiles(values); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := quantiles(nil); got != nil {
+		t.Errorf("Expected no quantiles for no values, got %v", got)
+	}
+}
+
+func TestSummary_Print(t *testing.T) {
+	movies, ratings := setupTestData()
+
+	var out bytes.Buffer
+	Summarize(FilterMoviesSync(movies, ratings, DefaultConfig()), ratings).Print(&out)
+
+	for _, expected := range []string{"Summary of 8 results", "Drama        3", "2020s        5", "180+ min     1", "Vote quantiles:     p10=2000"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type titleMatchMode int
+
+const (
+	titleMatchSubstring titleMatchMode = iota
+	titleMatchWord
+	titleMatchRegex
+)
+
+func (m titleMatchMode) String() string {
+	switch m {
+	case titleMatchWord:
+		return "word"
+	case titleMatchRegex:
+		return "regex"
+	default:
+		return "substring"
+	}
+}
+
+type titleSearch struct {
+	source        string // As entered, before normalizing
+	query         string
+	words         []string
+	mode          titleMatchMode
+	ignoreAccents bool
+	re            *regexp.Regexp
+}
+
+func newTitleSearch(query, mode string, ignoreAccents bool) (*titleSearch, error) {
+	ts := &titleSearch{
+		source:        query,
+		ignoreAccents: ignoreAccents,
+		query:         normalizeTitle(query, ignoreAccents),
+	}
+
+	switch strings.ToLower(mode) {
+	case "", "substring":
+		ts.mode = titleMatchSubstring
+	case "word":
+		ts.mode = titleMatchWord
+		ts.words = titleWords(ts.query)
+		if len(ts.words) == 0 {
+			return nil, fmt.Errorf("title search %q contains no words", query)
+		}
+	case "regex":
+		ts.mode = titleMatchRegex
+		// Lowercasing the pattern would change escapes such as \D into \d, so it
+		// only has its accents folded to match the normalized titles
+		pattern := query
+		if ignoreAccents {
+			pattern = foldAccents(pattern)
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title regex: %w", err)
+		}
+		ts.re = re
+	default:
+		return nil, fmt.Errorf("unknown title match mode: %s", mode)
+	}
+
+	return ts, nil
+}
+
+func (ts *titleSearch) Match(movie Movie, _ Rating, _ bool) bool {
+	if ts.matches(movie.PrimaryTitle) || ts.matches(movie.originalTitle) {
+		return true
+	}
+	for _, aka := range movie.akas {
+		if ts.matches(aka) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts *titleSearch) matches(title string) bool {
+	if title == "" {
+		return false
+	}
+	title = normalizeTitle(title, ts.ignoreAccents)
+
+	switch ts.mode {
+	case titleMatchWord:
+		return containsWords(titleWords(title), ts.words)
+	case titleMatchRegex:
+		return ts.re.MatchString(title)
+	default:
+		return strings.Contains(title, ts.query)
+	}
+}
+
+func normalizeTitle(title string, ignoreAccents bool) string {
+	title = strings.ToLower(title)
+	if ignoreAccents {
+		title = foldAccents(title)
+	}
+	return title
+}
+
+func titleWords(title string) []string {
+	return strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsWords reports whether want appears as a consecutive run in words
+func containsWords(words, want []string) bool {
+	for i := 0; i+len(want) <= len(words); i++ {
+		matched := true
+		for j := range want {
+			if words[i+j] != want[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j",
+	'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'œ': "oe",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'ß': "ss", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s",
+	'ţ': "t", 'ť': "t", 'ŧ': "t",
+	'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w",
+	'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// foldAccents maps accented Latin letters to their unaccented form, expects lowercase input
+func foldAccents(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := accentFolds[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,546 @@
+package search
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/apkatsikas/imdb-enhanced-search/term"
+)
+
+const (
+	tuiFilterWidth   = 44
+	tuiDetailsHeight = 8
+	tuiHelp          = "Tab switch panel  ↑↓ move  Enter edit/open  d site  r/v/y sort  w/n/l mark  q quit"
+)
+
+type tuiFocus int
+
+const (
+	focusFilters tuiFocus = iota
+	focusResults
+)
+
+type tuiColumn int
+
+const (
+	columnNone tuiColumn = iota
+	columnRating
+	columnVotes
+	columnYear
+)
+
+// tuiModel is the state of the full-screen interface, kept apart from the
+// terminal so it can be driven by tests
+type tuiModel struct {
+	data    *Dataset
+	cfg     config
+	weights ratingWeights
+
+	destinations []Destination
+	destination  int
+	printOnly    bool
+
+	results    []Movie
+	generation int // Bumped for every filter run so stale results are dropped
+	filtering  bool
+	dirty      bool // The config changed since the last filter run started
+
+	focus   tuiFocus
+	field   int
+	editing bool
+	edit    []rune
+	before  config // The config to restore if an edit is cancelled
+
+	row, offset int
+	pageSize    int
+	sortColumn  tuiColumn
+	ascending   bool
+
+	status string
+	quit   bool
+}
+
+type tuiResults struct {
+	generation int
+	results    []Movie
+	weights    ratingWeights
+}
+
+// RunTUI shows a full-screen interface with a filter panel, a sortable results
+// table and a details pane, re-filtering as the filters are edited. It returns
+// when the user quits.
+func RunTUI(imdbTitleUrl string, data *Dataset, cfg config) error {
+	restore, err := term.MakeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	keys := make(chan []term.Key)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- term.ParseKeys(buf[:n])
+		}
+	}()
+
+	resize := make(chan os.Signal, 1)
+	term.NotifyResize(resize)
+	defer signal.Stop(resize)
+
+	model := newTUIModel(imdbTitleUrl, data, cfg)
+	filtered := make(chan tuiResults)
+	// Each edit cancels the run started for the one before, so typing does not
+	// pile up filter runs over the whole dataset
+	cancel := context.CancelFunc(func() {})
+	defer func() { cancel() }()
+
+	for !model.quit {
+		if model.dirty {
+			cancel()
+			ctx, stop := context.WithCancel(context.Background())
+			cancel = stop
+			generation, cfg := model.startFilter()
+			go func() {
+				results, err := filterMoviesContext(ctx, data.Movies, data.Ratings, cfg)
+				if err != nil {
+					return
+				}
+				select {
+				case filtered <- tuiResults{generation, results, cfg.weights.resolve(data.Ratings)}:
+				case <-ctx.Done():
+				}
+			}()
+		}
+
+		width, height, err := term.Size(os.Stdout)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, "\x1b[H")
+		fmt.Fprint(out, strings.Join(model.render(width, height), "\x1b[K\r\n"))
+		out.Flush()
+
+		select {
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range pressed {
+				model.handleKey(key)
+			}
+		case result := <-filtered:
+			model.applyResults(result)
+		case <-resize:
+		}
+	}
+
+	return nil
+}
+
+func newTUIModel(imdbTitleUrl string, data *Dataset, cfg config) *tuiModel {
+	destinations, destination := cfg.destinationList(imdbTitleUrl)
+	return &tuiModel{
+		data:         data,
+		cfg:          cfg,
+		weights:      cfg.weights,
+		destinations: destinations,
+		destination:  destination,
+		printOnly:    cfg.cannotOpen() != nil,
+		dirty:        true,
+		pageSize:     1,
+	}
+}
+
+// startFilter marks a filter run as started, returning its generation and config
+func (m *tuiModel) startFilter() (int, config) {
+	m.generation++
+	m.filtering = true
+	m.dirty = false
+	return m.generation, m.cfg
+}
+
+func (m *tuiModel) applyResults(result tuiResults) {
+	if result.generation != m.generation {
+		return
+	}
+
+	m.filtering = false
+	m.results = result.results
+	m.weights = result.weights
+	m.sortResults()
+	m.row = min(m.row, max(len(m.results)-1, 0))
+}
+
+func (m *tuiModel) sortResults() {
+	if m.sortColumn == columnNone {
+		return
+	}
+
+	slices.SortStableFunc(m.results, func(a, b Movie) int {
+		c := cmp.Compare(m.columnValue(a), m.columnValue(b))
+		if !m.ascending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+}
+
+func (m *tuiModel) columnValue(movie Movie) float64 {
+	rating := m.data.Ratings[movie.Id]
+
+	switch m.sortColumn {
+	case columnVotes:
+		return float64(rating.NumVotes)
+	case columnYear:
+		if movie.StartYear == nil {
+			return 0
+		}
+		return float64(*movie.StartYear)
+	default:
+		return rating.AverageRating
+	}
+}
+
+func (m *tuiModel) handleKey(key term.Key) {
+	if key.Code == term.KeyCtrlC {
+		m.quit = true
+		return
+	}
+	if m.editing {
+		m.handleEditKey(key)
+		return
+	}
+
+	m.status = ""
+	switch {
+	case key.Code == term.KeyTab:
+		m.focus = 1 - m.focus
+	case key.Code == term.KeyRune && key.Rune == 'q':
+		m.quit = true
+	case key.Code == term.KeyRune && key.Rune == 'r':
+		m.sortBy(columnRating)
+	case key.Code == term.KeyRune && key.Rune == 'v':
+		m.sortBy(columnVotes)
+	case key.Code == term.KeyRune && key.Rune == 'y':
+		m.sortBy(columnYear)
+	case m.focus == focusFilters:
+		m.handleFilterKey(key)
+	default:
+		m.handleResultKey(key)
+	}
+}
+
+func (m *tuiModel) handleFilterKey(key term.Key) {
+	switch key.Code {
+	case term.KeyUp:
+		m.field = max(m.field-1, 0)
+	case term.KeyDown:
+		m.field = min(m.field+1, len(configFields)-1)
+	case term.KeyEnter:
+		m.editing = true
+		m.edit = []rune(configFields[m.field].get(m.cfg))
+		m.before = m.cfg
+	}
+}
+
+// handleEditKey applies the edited value as it is typed, so results follow along
+// whenever the value so far is valid
+func (m *tuiModel) handleEditKey(key term.Key) {
+	switch key.Code {
+	case term.KeyEnter:
+		m.editing = false
+		m.status = ""
+		return
+	case term.KeyEscape:
+		m.editing = false
+		m.status = ""
+		m.cfg = m.before
+		m.dirty = true
+		return
+	case term.KeyBackspace:
+		if len(m.edit) > 0 {
+			m.edit = m.edit[:len(m.edit)-1]
+		}
+	case term.KeyRune:
+		m.edit = append(m.edit, key.Rune)
+	default:
+		return
+	}
+
+	field := configFields[m.field]
+	updated := m.cfg
+	if err := updated.Set(field.name, string(m.edit)); err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.status = ""
+	if field.get(updated) != field.get(m.cfg) {
+		m.cfg = updated
+		m.dirty = true
+	}
+}
+
+func (m *tuiModel) handleResultKey(key term.Key) {
+	switch key.Code {
+	case term.KeyUp:
+		m.row--
+	case term.KeyDown:
+		m.row++
+	case term.KeyPageUp:
+		m.row -= m.pageSize
+	case term.KeyPageDown:
+		m.row += m.pageSize
+	case term.KeyHome:
+		m.row = 0
+	case term.KeyEnd:
+		m.row = len(m.results) - 1
+	case term.KeyEnter:
+		m.openSelected()
+	case term.KeyRune:
+		switch key.Rune {
+		case 'o':
+			m.openSelected()
+		case 'd':
+			m.destination = (m.destination + 1) % len(m.destinations)
+			m.status = "Opening titles on " + m.destinations[m.destination].Name
+		case 'w', 'n':
+			m.markSelected(key.Rune == 'w')
+		case 'l':
+			m.saveSelected()
+		}
+	}
+	m.row = max(min(m.row, len(m.results)-1), 0)
+}
+
+func (m *tuiModel) sortBy(column tuiColumn) {
+	if m.sortColumn == column {
+		m.ascending = !m.ascending
+	} else {
+		m.sortColumn, m.ascending = column, false
+	}
+	m.sortResults()
+}
+
+func (m *tuiModel) selected() (Movie, bool) {
+	if m.row < 0 || m.row >= len(m.results) {
+		return Movie{}, false
+	}
+	return m.results[m.row], true
+}
+
+func (m *tuiModel) openSelected() {
+	movie, ok := m.selected()
+	if !ok {
+		return
+	}
+
+	destination := m.destinations[m.destination]
+	url := destination.URL(movie)
+	if m.printOnly {
+		m.status = url
+		return
+	}
+	if err := m.cfg.linkOpener().Open(url); err != nil {
+		m.status = fmt.Sprintf("Error opening browser: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Opened %s on %s", movie.PrimaryTitle, destination.Name)
+}
+
+func (m *tuiModel) markSelected(watched bool) {
+	movie, ok := m.selected()
+	if !ok || m.cfg.history == nil {
+		return
+	}
+
+	mark, label := m.cfg.history.MarkDismissed, "not interested"
+	if watched {
+		mark, label = m.cfg.history.MarkWatched, "watched"
+	}
+	if err := mark(movie.Id); err != nil {
+		m.status = "Error saving history: " + err.Error()
+		return
+	}
+
+	m.status = fmt.Sprintf("Marked %s as %s", movie.PrimaryTitle, label)
+	if !m.cfg.includeSeen {
+		m.results = slices.Delete(m.results, m.row, m.row+1)
+	}
+}
+
+func (m *tuiModel) saveSelected() {
+	movie, ok := m.selected()
+	if !ok || m.cfg.watchlist == nil {
+		return
+	}
+
+	if err := m.cfg.watchlist.AddToWatchlist(movie.Id, movie.PrimaryTitle); err != nil {
+		m.status = "Error saving watchlist: " + err.Error()
+		return
+	}
+	if !m.cfg.watchlist.OnWatchlist(movie.Id) {
+		m.status = fmt.Sprintf("%s is already watched or dismissed", movie.PrimaryTitle)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %s to your watchlist", movie.PrimaryTitle)
+}
+
+// render draws the whole screen as height lines of width columns
+func (m *tuiModel) render(width, height int) []string {
+	bodyHeight := max(height-tuiDetailsHeight-3, 1)
+	filterWidth := min(tuiFilterWidth, width/2)
+	tableWidth := max(width-filterWidth-1, 0)
+	m.pageSize = max(bodyHeight-1, 1)
+
+	count := fmt.Sprintf("%d results", len(m.results))
+	if m.filtering {
+		count += " (filtering...)"
+	}
+	lines := []string{fitLine("IMDB Enhanced Search - "+count, width)}
+
+	filters := m.renderFilters(filterWidth, bodyHeight)
+	table := m.renderTable(tableWidth, bodyHeight)
+	for i := range bodyHeight {
+		lines = append(lines, filters[i]+"│"+table[i])
+	}
+
+	lines = append(lines, strings.Repeat("─", width))
+	details := make([]string, tuiDetailsHeight)
+	if movie, ok := m.selected(); ok {
+		rating, hasRating := m.data.Ratings[movie.Id]
+		copy(details, strings.Split(formatDetails(movie, rating, hasRating, m.weights), "\n"))
+	}
+	for _, line := range details {
+		lines = append(lines, fitLine(line, width))
+	}
+
+	status := m.status
+	if status == "" {
+		status = tuiHelp
+	}
+	return append(lines, fitLine(status, width))
+}
+
+func (m *tuiModel) renderFilters(width, height int) []string {
+	lines := make([]string, height)
+	labelWidth := 0
+	for _, field := range configFields {
+		labelWidth = max(labelWidth, len(field.label))
+	}
+
+	first := max(0, m.field-height+1)
+	for i := range lines {
+		index := first + i
+		if index >= len(configFields) {
+			lines[i] = fitLine("", width)
+			continue
+		}
+
+		field := configFields[index]
+		value := field.get(m.cfg)
+		if m.editing && index == m.field {
+			value = string(m.edit) + "_"
+		}
+		line := fitLine(fmt.Sprintf(" %-*s %s", labelWidth, field.label, value), width)
+		if index == m.field && m.focus == focusFilters {
+			line = highlight(line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (m *tuiModel) renderTable(width, height int) []string {
+	const fixed = 6 + 1 + 6 + 1 + 7 + 1 + 10 + 1 + 4
+	titleWidth := max(width-fixed-1, 5)
+
+	lines := make([]string, height)
+	lines[0] = fitLine(fmt.Sprintf(" %5s %-*s %6s %7s %10s %4s", "#", titleWidth, "Title",
+		"Year"+m.sortMarker(columnYear), "Rating"+m.sortMarker(columnRating),
+		"Votes"+m.sortMarker(columnVotes), "Min"), width)
+
+	rows := height - 1
+	if m.row < m.offset {
+		m.offset = m.row
+	}
+	if m.row >= m.offset+rows {
+		m.offset = m.row - rows + 1
+	}
+
+	for i := range rows {
+		index := m.offset + i
+		if index >= len(m.results) {
+			lines[i+1] = fitLine("", width)
+			continue
+		}
+
+		movie := m.results[index]
+		rating, hasRating := m.data.Ratings[movie.Id]
+		year, runtime, ratingText, votesText := "-", "-", "-", "-"
+		if movie.StartYear != nil {
+			year = strconv.Itoa(*movie.StartYear)
+		}
+		if movie.runtimeMinutes != nil {
+			runtime = strconv.Itoa(*movie.runtimeMinutes)
+		}
+		if hasRating {
+			ratingText = strconv.FormatFloat(rating.AverageRating, 'f', 1, 64)
+			votesText = strconv.Itoa(rating.NumVotes)
+		}
+		line := fitLine(fmt.Sprintf(" %5d %s %6s %7s %10s %4s", index+1, fitLine(movie.PrimaryTitle, titleWidth),
+			year, ratingText, votesText, runtime), width)
+		if index == m.row && m.focus == focusResults {
+			line = highlight(line)
+		}
+		lines[i+1] = line
+	}
+	return lines
+}
+
+func (m *tuiModel) sortMarker(column tuiColumn) string {
+	switch {
+	case m.sortColumn != column:
+		return ""
+	case m.ascending:
+		return "↑"
+	default:
+		return "↓"
+	}
+}
+
+// fitLine pads or truncates s to exactly width runes
+func fitLine(s string, width int) string {
+	width = max(width, 0)
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func highlight(s string) string {
+	return "\x1b[7m" + s + "\x1b[0m"
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/apkatsikas/imdb-enhanced-search/term"
+)
+
+// runFilter completes a pending filter run synchronously, as RunTUI would
+func runFilter(m *tuiModel) {
+	generation, cfg := m.startFilter()
+	m.applyResults(tuiResults{generation, FilterMovies(m.data.Movies, m.data.Ratings, cfg), cfg.weights})
+}
+
+func typeKeys(m *tuiModel, text string) {
+	for _, r := range text {
+		m.handleKey(term.Key{Code: term.KeyRune, Rune: r})
+	}
+}
+
+func newTestTUIModel() *tuiModel {
+	movies, ratings := setupTestData()
+	m := newTUIModel("https://www.imdb.com/title", &Dataset{Movies: movies, Ratings: ratings}, DefaultConfig())
+	runFilter(m)
+	return m
+}
+
+func resultIds(m *tuiModel) []string {
+	ids := make([]string, len(m.results))
+	for i, movie := range m.results {
+		ids[i] = movie.Id
+	}
+	return ids
+}
+
+func TestTUI_EditingRefiltersAsValuesChange(t *testing.T) {
+	m := newTestTUIModel()
+	if len(m.results) != 8 {
+		t.Fatalf("Expected 8 results, got %d", len(m.results))
+	}
+
+	m.field = slices.IndexFunc(configFields, func(f configField) bool { return f.name == "minYear" })
+	m.handleKey(term.Key{Code: term.KeyEnter})
+	typeKeys(m, "202")
+	if !m.dirty {
+		t.Fatal("Expected a valid partial value to trigger a re-filter")
+	}
+	typeKeys(m, "1")
+	runFilter(m)
+	if len(m.results) != 2 {
+		t.Errorf("Expected 2 results from 2021, got %d", len(m.results))
+	}
+
+	typeKeys(m, "x")
+	if m.status == "" || m.cfg.minYear != 2021 {
+		t.Errorf("Expected an invalid value to show an error and keep the last valid one, got %d", m.cfg.minYear)
+	}
+
+	m.handleKey(term.Key{Code: term.KeyEscape})
+	runFilter(m)
+	if m.editing || len(m.results) != 8 {
+		t.Errorf("Expected escape to cancel the edit and restore all 8 results, got %d", len(m.results))
+	}
+}
+
+func TestTUI_SortColumns(t *testing.T) {
+	m := newTestTUIModel()
+	m.handleKey(term.Key{Code: term.KeyTab})
+	typeKeys(m, "r")
+	if ids := resultIds(m); ids[0] != "3" || ids[7] != "4" {
+		t.Errorf("Expected results sorted by rating descending, got %v", ids)
+	}
+
+	typeKeys(m, "v")
+	if ids := resultIds(m); ids[0] != "6" || ids[7] != "8" {
+		t.Errorf("Expected results sorted by votes descending, got %v", ids)
+	}
+
+	typeKeys(m, "v")
+	if ids := resultIds(m); ids[0] != "8" || ids[7] != "6" {
+		t.Errorf("Expected a second press to sort votes ascending, got %v", ids)
+	}
+}
+
+func TestTUI_KeepsConfiguredSort(t *testing.T) {
+	movies, ratings := setupTestData()
+	cfg := DefaultConfig()
+	if err := cfg.Set("sort", "votes"); err != nil {
+		t.Fatal(err)
+	}
+	m := newTUIModel("https://www.imdb.com/title", &Dataset{Movies: movies, Ratings: ratings}, cfg)
+	runFilter(m)
+
+	if ids := resultIds(m); ids[0] != "6" || ids[7] != "8" {
+		t.Errorf("Expected the configured votes sort until a column is chosen, got %v", ids)
+	}
+}
+
+func TestTUI_StaleResultsIgnored(t *testing.T) {
+	m := newTestTUIModel()
+
+	stale, _ := m.startFilter()
+	m.startFilter()
+	m.applyResults(tuiResults{generation: stale})
+	if len(m.results) != 8 || !m.filtering {
+		t.Errorf("Expected results from an older filter run to be ignored, got %d results", len(m.results))
+	}
+}
+
+func TestTUI_RenderTinyScreen(t *testing.T) {
+	m := newTestTUIModel()
+	for _, size := range [][2]int{{0, 0}, {1, 1}, {10, 5}} {
+		if lines := m.render(size[0], size[1]); len(lines) == 0 {
+			t.Errorf("Expected lines for a %dx%d screen", size[0], size[1])
+		}
+	}
+}
+
+func TestTUI_Render(t *testing.T) {
+	m := newTestTUIModel()
+	m.handleKey(term.Key{Code: term.KeyTab})
+	typeKeys(m, "r")
+	m.handleKey(term.Key{Code: term.KeyDown})
+
+	lines := m.render(120, 30)
+	if len(lines) != 30 {
+		t.Fatalf("Expected 30 lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "8 results") {
+		t.Errorf("Expected the header to count results, got %q", lines[0])
+	}
+	screen := strings.Join(lines, "\n")
+	for _, expected := range []string{"Minimum year", "Rating↓", "Thriller Night", "Action Hero (2020)"} {
+		if !strings.Contains(screen, expected) {
+			t.Errorf("Expected the screen to contain %q", expected)
+		}
+	}
+}
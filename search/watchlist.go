@@ -0,0 +1,25 @@
+package search
+
+// Watchlist remembers titles the user wants to watch later. Like History it is
+// consulted by the FilterMovies worker pool, so it must be safe for concurrent use.
+type Watchlist interface {
+	OnWatchlist(id string) bool
+	AddToWatchlist(id, title string) error
+}
+
+type watchlistFilter struct {
+	watchlist Watchlist
+}
+
+func (f watchlistFilter) Match(movie Movie, _ Rating, _ bool) bool {
+	return f.watchlist.OnWatchlist(movie.Id)
+}
+
+func (f watchlistFilter) name() string { return "watchlist" }
+
+func (f watchlistFilter) reason(movie Movie, _ Rating, _ bool) string {
+	if f.watchlist.OnWatchlist(movie.Id) {
+		return "on your watchlist"
+	}
+	return "not on your watchlist"
+}
@@ -0,0 +1,257 @@
+// Package server exposes search over HTTP as a JSON API and a small web front
+// end, keeping the dataset loaded between requests
+package server
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/apkatsikas/imdb-enhanced-search/search"
+)
+
+const (
+	defaultPageSize   = 20
+	maxPageSize       = 100
+	defaultSearchSort = "weighted"
+
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+//go:embed web
+var webFiles embed.FS
+
+type Server struct {
+	data         atomic.Pointer[search.Dataset]
+	imdbTitleUrl string
+	mux          *http.ServeMux
+}
+
+type searchResponse struct {
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"pageSize"`
+	Results  []search.TitleDetails `json:"results"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func New(data *search.Dataset, imdbTitleUrl string) *Server {
+	s := &Server{
+		imdbTitleUrl: imdbTitleUrl,
+		mux:          http.NewServeMux(),
+	}
+	s.data.Store(data)
+
+	s.mux.HandleFunc("GET /api/search", s.handleSearch)
+	s.mux.HandleFunc("GET /api/titles/{id}", s.handleTitle)
+	s.mux.HandleFunc("GET /api/facets", s.handleFacets)
+	s.mux.HandleFunc("GET /api/random", s.handleRandom)
+
+	web, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	s.mux.Handle("GET /", http.FileServerFS(web))
+
+	return s
+}
+
+// Swap replaces the dataset for new requests. Requests in flight finish with the
+// dataset they started with, so no response mixes old and new data.
+func (s *Server) Swap(data *search.Dataset) {
+	s.data.Store(data)
+}
+
+// RefreshEvery calls load on every interval until ctx is cancelled, swapping in
+// each dataset it returns. The previous dataset keeps being served while a new
+// one loads, and when loading fails.
+func (s *Server) RefreshEvery(ctx context.Context, interval time.Duration, load func() (*search.Dataset, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		log.Println("Refreshing dataset")
+		data, err := load()
+		if err != nil {
+			log.Println("Error refreshing dataset, still serving the previous one:", err)
+			continue
+		}
+		s.Swap(data)
+		log.Printf("Now serving %d movies and %d ratings", len(data.Movies), len(data.Ratings))
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then waits for requests
+// in flight to finish
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	log.Printf("Listening on %s", addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleSearch filters with the settings in the query parameters, sorted by
+// weighted rating unless another sort is given, and returns one page
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	if !values.Has("sort") {
+		values.Set("sort", defaultSearchSort)
+	}
+
+	cfg, err := search.ConfigFromValues(values)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	page, pageSize, err := pagination(values)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data := s.data.Load()
+	results := search.FilterMovies(data.Movies, data.Ratings, cfg)
+	// Compare pages rather than multiplying, which could overflow for a huge page
+	start := len(results)
+	if page-1 <= len(results)/pageSize {
+		start = min((page-1)*pageSize, len(results))
+	}
+	end := min(start+pageSize, len(results))
+
+	writeJSON(w, http.StatusOK, searchResponse{
+		Total:    len(results),
+		Page:     page,
+		PageSize: pageSize,
+		Results:  s.describe(cfg.Describe(results[start:end], data.Ratings)),
+	})
+}
+
+func (s *Server) handleTitle(w http.ResponseWriter, r *http.Request) {
+	data := s.data.Load()
+	movie, found := data.Movies[r.PathValue("id")]
+	if !found {
+		writeError(w, http.StatusNotFound, errors.New("title not found"))
+		return
+	}
+
+	details := search.DefaultConfig().Describe([]search.Movie{movie}, data.Ratings)
+	writeJSON(w, http.StatusOK, s.describe(details)[0])
+}
+
+func (s *Server) handleFacets(w http.ResponseWriter, r *http.Request) {
+	cfg, err := search.ConfigFromValues(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data := s.data.Load()
+	_, summary := search.FilterMoviesWithSummary(data.Movies, data.Ratings, cfg)
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// handleRandom picks one matching title, uniformly unless a sample sort is given
+func (s *Server) handleRandom(w http.ResponseWriter, r *http.Request) {
+	cfg, err := search.ConfigFromValues(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data := s.data.Load()
+	results := search.FilterMovies(data.Movies, data.Ratings, cfg)
+	if len(results) == 0 {
+		writeError(w, http.StatusNotFound, errors.New("no titles match"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.describe(cfg.Describe(results[:1], data.Ratings))[0])
+}
+
+// describe fills in the IMDB link for each title
+func (s *Server) describe(details []search.TitleDetails) []search.TitleDetails {
+	for i := range details {
+		details[i].URL = fmt.Sprintf("%s/%s/", s.imdbTitleUrl, details[i].Id)
+	}
+	return details
+}
+
+func pagination(values url.Values) (int, int, error) {
+	page, pageSize := 1, defaultPageSize
+
+	if value := values.Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("invalid page: %q", value)
+		}
+		page = parsed
+	}
+	if value := values.Get("pageSize"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			return 0, 0, fmt.Errorf("invalid pageSize: %q, must be between 1 and %d", value, maxPageSize)
+		}
+		pageSize = parsed
+	}
+
+	return page, pageSize, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Println("Error writing response:", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,329 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/imdb-enhanced-search/search"
+)
+
+const testTitleUrl = "https://www.imdb.com/title"
+
+const testBasics = `tconst	titleType	primaryTitle	originalTitle	isAdult	startYear	endYear	runtimeMinutes	genres
+tt0000001	movie	Alien	Alien	0	1979	\N	117	Horror,Sci-Fi
+tt0000002	movie	Aliens	Aliens	0	1986	\N	137	Action,Adventure,Sci-Fi
+tt0000003	movie	The Thing	The Thing	0	1982	\N	109	Horror,Mystery,Sci-Fi
+tt0000004	movie	Paddington	Paddington	0	2014	\N	95	Adventure,Comedy,Family
+tt0000005	movie	Unrated Oddity	Unrated Oddity	0	2001	\N	80	Drama
+`
+
+const testRatings = `tconst	averageRating	numVotes
+tt0000001	8.5	900000
+tt0000002	8.4	750000
+tt0000003	8.2	450000
+tt0000004	7.3	120000
+`
+
+func loadTestData(t *testing.T) *search.Dataset {
+	t.Helper()
+	return loadTestDataFrom(t, testBasics, testRatings)
+}
+
+func loadTestDataFrom(t *testing.T, basicsTsv, ratingsTsv string) *search.Dataset {
+	t.Helper()
+	dir := t.TempDir()
+	basics := filepath.Join(dir, "title.basics.tsv")
+	ratings := filepath.Join(dir, "title.ratings.tsv")
+	if err := os.WriteFile(basics, []byte(basicsTsv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ratings, []byte(ratingsTsv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := search.LoadDataset(basics, ratings, "")
+	if err != nil {
+		t.Fatalf("Unexpected error loading test data: %v", err)
+	}
+	return data
+}
+
+func get(t *testing.T, handler http.Handler, target string, body any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON response, got %s", contentType)
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(body); err != nil {
+		t.Fatalf("Unexpected error decoding %s: %v", target, err)
+	}
+	return recorder.Code
+}
+
+func TestSearch(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	var response searchResponse
+	if status := get(t, s, "/api/search?genres=Sci-Fi&minYear=1980", &response); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if response.Total != 2 || len(response.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %+v", response)
+	}
+	if first := response.Results[0]; first.Title != "Aliens" || first.URL != testTitleUrl+"/tt0000002/" {
+		t.Errorf("Expected Aliens first by weighted rating with its IMDB link, got %+v", first)
+	}
+}
+
+func TestSearch_Pagination(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	var ids []string
+	for _, page := range []string{"1", "2", "3"} {
+		var response searchResponse
+		if status := get(t, s, "/api/search?sort=votes&pageSize=2&page="+page, &response); status != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if response.Total != 4 || response.PageSize != 2 {
+			t.Errorf("Expected 4 results in pages of 2, got %+v", response)
+		}
+		for _, result := range response.Results {
+			ids = append(ids, result.Id)
+		}
+	}
+
+	if expected := "tt0000001,tt0000002,tt0000003,tt0000004"; strings.Join(ids, ",") != expected {
+		t.Errorf("Expected pages to list %s, got %v", expected, ids)
+	}
+}
+
+func TestSearch_PageBeyondResults(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	for _, page := range []string{"3", "9223372036854775807"} {
+		var response searchResponse
+		if status := get(t, s, "/api/search?pageSize=20&page="+page, &response); status != http.StatusOK {
+			t.Fatalf("Expected status 200 for page %s, got %d", page, status)
+		}
+		if response.Total != 4 || len(response.Results) != 0 {
+			t.Errorf("Expected an empty page %s of 4 results, got %+v", page, response)
+		}
+	}
+}
+
+func TestSearch_BadRequest(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	for _, target := range []string{
+		"/api/search?minYear=soon",
+		"/api/search?query=year%20%3E%3D",
+		"/api/search?pageSize=1000",
+		"/api/search?page=0",
+		"/api/facets?missingRating=sometimes",
+		"/api/random?sort=length",
+	} {
+		var response errorResponse
+		if status := get(t, s, target, &response); status != http.StatusBadRequest || response.Error == "" {
+			t.Errorf("Expected a 400 with an error for %s, got %d %+v", target, status, response)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	var title search.TitleDetails
+	if status := get(t, s, "/api/titles/tt0000003", &title); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if title.Title != "The Thing" || *title.Year != 1982 || *title.Rating != 8.2 || title.Votes != 450000 {
+		t.Errorf("Unexpected title details: %+v", title)
+	}
+
+	var unrated search.TitleDetails
+	get(t, s, "/api/titles/tt0000005", &unrated)
+	if unrated.Rating != nil || unrated.WeightedRating != nil {
+		t.Errorf("Expected no rating for an unrated title, got %+v", unrated)
+	}
+
+	var missing errorResponse
+	if status := get(t, s, "/api/titles/tt9999999", &missing); status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+}
+
+func TestFacets(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	var summary search.Summary
+	if status := get(t, s, "/api/facets?genres=Sci-Fi", &summary); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if summary.Total != 3 || summary.Genres["Horror"] != 2 || summary.Decades[1970] != 1 {
+		t.Errorf("Unexpected facets: %+v", summary)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	var title search.TitleDetails
+	if status := get(t, s, "/api/random?genres=Family", &title); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if title.Id != "tt0000004" {
+		t.Errorf("Expected the only family title, got %+v", title)
+	}
+
+	var response errorResponse
+	if status := get(t, s, "/api/random?minYear=2030", &response); status != http.StatusNotFound {
+		t.Errorf("Expected status 404 when nothing matches, got %d", status)
+	}
+}
+
+func TestListenAndServe_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- New(loadTestData(t), testTitleUrl).ListenAndServe(ctx, addr)
+	}()
+
+	var response *http.Response
+	for range 50 {
+		if response, err = http.Get("http://" + addr + "/api/titles/tt0000001"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Server did not start: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", response.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
+
+func TestWebUI(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+
+	for target, expected := range map[string]string{
+		"/":          `<form id="search">`,
+		"/app.js":    `getJSON("api/random"`,
+		"/style.css": "#results",
+	} {
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %s, got %d", target, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Errorf("Expected %s to contain %q", target, expected)
+		}
+	}
+}
+
+// freshBasics adds a title to the test data, as a newer IMDB dump would
+const freshBasics = testBasics + "tt0000006\tmovie\tNope\tNope\t0\t2022\t\\N\t130\tHorror,Mystery,Sci-Fi\n"
+const freshRatings = testRatings + "tt0000006\t6.8\t250000\n"
+
+func TestSwap_ConcurrentQueries(t *testing.T) {
+	old, fresh := loadTestData(t), loadTestDataFrom(t, freshBasics, freshRatings)
+	s := New(old, testTitleUrl)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				recorder := httptest.NewRecorder()
+				s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/search?genres=Sci-Fi&pageSize=100", nil))
+
+				var response searchResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+					t.Errorf("Unexpected error decoding response: %v", err)
+					return
+				}
+				// Either dataset is fine, but the total and the results must come from the same one
+				if response.Total != len(response.Results) || (response.Total != 3 && response.Total != 4) {
+					t.Errorf("Expected 3 or 4 consistent results, got total %d with %d results",
+						response.Total, len(response.Results))
+				}
+			}
+		}()
+	}
+
+	for i := range 100 {
+		if i%2 == 0 {
+			s.Swap(fresh)
+		} else {
+			s.Swap(old)
+		}
+	}
+	wg.Wait()
+}
+
+type loadResult struct {
+	data *search.Dataset
+	err  error
+}
+
+func TestRefreshEvery(t *testing.T) {
+	s := New(loadTestData(t), testTitleUrl)
+	fresh := loadTestDataFrom(t, freshBasics, freshRatings)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Each load waits for the test to hand it a result, so a send only completes
+	// once the refresher has finished with the previous one
+	results := make(chan loadResult)
+	go s.RefreshEvery(ctx, time.Millisecond, func() (*search.Dataset, error) {
+		result := <-results
+		return result.data, result.err
+	})
+
+	results <- loadResult{err: errors.New("download failed")}
+	results <- loadResult{err: errors.New("download failed again")}
+	var missing errorResponse
+	if status := get(t, s, "/api/titles/tt0000006", &missing); status != http.StatusNotFound {
+		t.Errorf("Expected the previous dataset to be kept after a failed refresh, got status %d", status)
+	}
+
+	results <- loadResult{data: fresh}
+	results <- loadResult{err: errors.New("download failed")}
+	var title search.TitleDetails
+	if status := get(t, s, "/api/titles/tt0000006", &title); status != http.StatusOK || title.Title != "Nope" {
+		t.Errorf("Expected the refreshed dataset to be served, got status %d %+v", status, title)
+	}
+}
@@ -0,0 +1,108 @@
+"use strict";
+
+const form = document.getElementById("search");
+const status = document.getElementById("status");
+const pick = document.getElementById("pick");
+const results = document.getElementById("results");
+const pages = document.getElementById("pages");
+
+let page = 1;
+
+// params turns the filled in form fields into API query parameters
+function params() {
+	const values = new URLSearchParams();
+	for (const [name, value] of new FormData(form)) {
+		if (value.trim() !== "") {
+			values.set(name, value.trim());
+		}
+	}
+	// A match mode on its own is rejected, so only send it with a title
+	if (!values.has("title")) {
+		values.delete("titleMode");
+	}
+	return values;
+}
+
+async function getJSON(path, values) {
+	const response = await fetch(`${path}?${values}`);
+	const body = await response.json();
+	if (!response.ok) {
+		throw new Error(body.error);
+	}
+	return body;
+}
+
+function card(title) {
+	const article = document.createElement("article");
+
+	const link = document.createElement("a");
+	link.href = title.url;
+	link.target = "_blank";
+	link.rel = "noopener";
+	link.textContent = title.title;
+
+	const heading = document.createElement("h2");
+	heading.append(link, ` (${title.year ?? "unknown"})`);
+
+	const details = document.createElement("p");
+	const rating = title.rating == null
+		? "No rating"
+		: `${title.rating.toFixed(1)}, weighted ${title.weightedRating.toFixed(2)} (${title.votes.toLocaleString()} votes)`;
+	details.textContent = `${title.runtime ?? "?"} min · ${title.genres.join(", ")} · ${rating}`;
+
+	article.append(heading, details);
+	return article;
+}
+
+async function search() {
+	const values = params();
+	values.set("page", page);
+	status.textContent = "Searching...";
+	pick.hidden = true;
+
+	try {
+		const body = await getJSON("api/search", values);
+		const lastPage = Math.max(1, Math.ceil(body.total / body.pageSize));
+		status.textContent = `Found ${body.total.toLocaleString()} titles`;
+		results.replaceChildren(...body.results.map(card));
+		document.getElementById("page").textContent = `Page ${body.page} of ${lastPage}`;
+		document.getElementById("previous").disabled = body.page <= 1;
+		document.getElementById("next").disabled = body.page >= lastPage;
+		pages.hidden = body.total === 0;
+	} catch (error) {
+		status.textContent = error.message;
+	}
+}
+
+async function roll() {
+	const values = params();
+	if (values.get("sort") !== "sample") {
+		values.delete("sort");
+	}
+	status.textContent = "Rolling...";
+
+	try {
+		const title = await getJSON("api/random", values);
+		status.textContent = "How about this one?";
+		pick.replaceChildren(card(title));
+		pick.hidden = false;
+	} catch (error) {
+		status.textContent = error.message;
+		pick.hidden = true;
+	}
+}
+
+form.addEventListener("submit", (event) => {
+	event.preventDefault();
+	page = 1;
+	search();
+});
+document.getElementById("roll").addEventListener("click", roll);
+document.getElementById("previous").addEventListener("click", () => {
+	page--;
+	search();
+});
+document.getElementById("next").addEventListener("click", () => {
+	page++;
+	search();
+});
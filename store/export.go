@@ -0,0 +1,32 @@
+package store
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WriteCSV writes entries with the Const column IMDB uses for list imports,
+// plus a link to each title under titleUrl
+func WriteCSV(w io.Writer, entries []Entry, titleUrl string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"Const", "Title", "Added", "URL"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			entry.Id,
+			entry.Title,
+			entry.Updated.Format(time.DateOnly),
+			fmt.Sprintf("%s/%s/", titleUrl, entry.Id),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
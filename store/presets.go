@@ -0,0 +1,116 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const presetsFileName = "presets.json"
+
+// Preset is a saved set of search settings, by setting name
+type Preset map[string]string
+
+// Presets persists named search settings as a JSON file. It is safe for
+// concurrent use.
+type Presets struct {
+	path    string
+	mu      sync.RWMutex
+	presets map[string]Preset
+}
+
+type presetsFile struct {
+	Presets map[string]Preset `json:"presets"`
+}
+
+// DefaultPresetsPath is presets.json in the directory of the given profile
+func DefaultPresetsPath(profile string) (string, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, presetsFileName), nil
+}
+
+// OpenPresets loads the presets at path, a missing file is treated as having none
+func OpenPresets(path string) (*Presets, error) {
+	p := &Presets{path: path, presets: make(map[string]Preset)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading presets: %w", err)
+	}
+
+	var file presetsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing presets %s: %w", path, err)
+	}
+	if file.Presets != nil {
+		p.presets = file.Presets
+	}
+
+	return p, nil
+}
+
+// Names lists the saved presets alphabetically
+func (p *Presets) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Sorted(maps.Keys(p.presets))
+}
+
+func (p *Presets) Get(name string) (Preset, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	preset, found := p.presets[name]
+	return maps.Clone(preset), found
+}
+
+// Save stores preset under name, replacing any preset already called that
+func (p *Presets) Save(name string, preset Preset) error {
+	if name == "" {
+		return errors.New("a preset needs a name")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.presets[name] = maps.Clone(preset)
+	return p.save()
+}
+
+// Delete removes the preset called name, reporting whether there was one
+func (p *Presets) Delete(name string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, found := p.presets[name]; !found {
+		return false, nil
+	}
+	delete(p.presets, name)
+	return true, p.save()
+}
+
+func (p *Presets) save() error {
+	data, err := json.MarshalIndent(presetsFile{Presets: p.presets}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("creating presets directory: %w", err)
+	}
+
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing presets: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPresets_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", presetsFileName)
+
+	p, err := OpenPresets(path)
+	if err != nil {
+		t.Fatalf("Unexpected error opening missing presets: %v", err)
+	}
+	if err := p.Save("horror", Preset{"genres": "Horror", "maxRuntime": "110"}); err != nil {
+		t.Fatalf("Unexpected error saving preset: %v", err)
+	}
+	if err := p.Save("classics", Preset{"maxYear": "1970"}); err != nil {
+		t.Fatalf("Unexpected error saving preset: %v", err)
+	}
+	if err := p.Save("", Preset{}); err == nil {
+		t.Error("Expected an error saving a preset without a name")
+	}
+
+	reopened, err := OpenPresets(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reopening presets: %v", err)
+	}
+	if names := reopened.Names(); !slices.Equal(names, []string{"classics", "horror"}) {
+		t.Errorf("Expected classics and horror, got %v", names)
+	}
+	if preset, found := reopened.Get("horror"); !found || !maps.Equal(preset, Preset{"genres": "Horror", "maxRuntime": "110"}) {
+		t.Errorf("Unexpected horror preset: %v", preset)
+	}
+
+	if deleted, err := reopened.Delete("classics"); !deleted || err != nil {
+		t.Errorf("Expected classics to be deleted, got %t %v", deleted, err)
+	}
+	if deleted, _ := reopened.Delete("classics"); deleted {
+		t.Error("Expected deleting a missing preset to report nothing deleted")
+	}
+	if _, found := reopened.Get("classics"); found {
+		t.Error("Expected classics to be gone")
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultProfile keeps its files directly in the config directory, as they
+	// were before profiles existed
+	DefaultProfile  = "default"
+	profilesDirName = "profiles"
+)
+
+// ProfileDir is where the profile called name keeps its history and presets,
+// an empty name meaning the default profile
+func ProfileDir(name string) (string, error) {
+	root, err := appDir()
+	if err != nil {
+		return "", err
+	}
+	return profileDir(root, name)
+}
+
+// Profiles lists the named profiles alphabetically, not including the default
+func Profiles() ([]string, error) {
+	root, err := appDir()
+	if err != nil {
+		return nil, err
+	}
+	return profiles(root)
+}
+
+// CreateProfile makes the directory for a new named profile, so it is listed
+// before anything has been saved to it
+func CreateProfile(name string) error {
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating profile directory: %w", err)
+	}
+	return nil
+}
+
+func appDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("finding config directory: %w", err)
+	}
+	return filepath.Join(configDir, appDirName), nil
+}
+
+func profileDir(root, name string) (string, error) {
+	if name == "" || name == DefaultProfile {
+		return root, nil
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return "", fmt.Errorf("invalid profile name: %q", name)
+	}
+	return filepath.Join(root, profilesDirName, name), nil
+}
+
+func profiles(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, profilesDirName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading profiles: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
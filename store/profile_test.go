@@ -0,0 +1,76 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestProfileDir(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name     string
+		expected string
+		hasError bool
+	}{
+		{name: "", expected: root},
+		{name: DefaultProfile, expected: root},
+		{name: "alice", expected: filepath.Join(root, profilesDirName, "alice")},
+		{name: "..", hasError: true},
+		{name: "../alice", hasError: true},
+		{name: `c:\alice`, hasError: true},
+	}
+
+	for _, tt := range tests {
+		dir, err := profileDir(root, tt.name)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("Expected an error for profile %q", tt.name)
+			}
+			continue
+		}
+		if err != nil || dir != tt.expected {
+			t.Errorf("Expected %s for profile %q, got %s (%v)", tt.expected, tt.name, dir, err)
+		}
+	}
+}
+
+func TestProfiles_KeepSeparateHistories(t *testing.T) {
+	root := t.TempDir()
+	if names, err := profiles(root); err != nil || len(names) != 0 {
+		t.Errorf("Expected no profiles yet, got %v (%v)", names, err)
+	}
+
+	open := func(profile string) *Store {
+		dir, err := profileDir(root, profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := Open(filepath.Join(dir, historyFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	if err := open("bob").MarkWatched("tt0078748"); err != nil {
+		t.Fatalf("Unexpected error marking watched: %v", err)
+	}
+	if err := open("").MarkWatched("tt0090605"); err != nil {
+		t.Fatalf("Unexpected error marking watched: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, profilesDirName, "alice"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if names, err := profiles(root); err != nil || !slices.Equal(names, []string{"alice", "bob"}) {
+		t.Errorf("Expected alice and bob, got %v (%v)", names, err)
+	}
+	if bob := open("bob"); !bob.Seen("tt0078748") || bob.Seen("tt0090605") {
+		t.Error("Expected bob to only see their own history")
+	}
+	if alice := open("alice"); alice.Seen("tt0078748") || alice.Seen("tt0090605") {
+		t.Error("Expected alice to start with an empty history")
+	}
+}
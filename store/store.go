@@ -0,0 +1,252 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	appDirName      = "imdb-enhanced-search"
+	historyFileName = "history.json"
+)
+
+type Status string
+
+const (
+	Watched   Status = "watched"
+	Dismissed Status = "dismissed"
+	Watchlist Status = "watchlist"
+)
+
+type Entry struct {
+	Id      string    `json:"id"`
+	Title   string    `json:"title,omitzero"`
+	Status  Status    `json:"status"`
+	Updated time.Time `json:"updated"`
+	Rating  int       `json:"rating,omitzero"` // The user's own rating out of 10
+	Rated   time.Time `json:"rated,omitzero"`
+	Source  string    `json:"source,omitzero"` // Where an imported entry came from
+}
+
+// Store persists what the user has done with titles as a JSON file. It is safe
+// for concurrent use.
+type Store struct {
+	path    string
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+type storeFile struct {
+	Entries []Entry `json:"entries"`
+}
+
+// DefaultPath is history.json in the directory of the given profile
+func DefaultPath(profile string) (string, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFileName), nil
+}
+
+// Open loads the store at path, a missing file is treated as an empty store
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing store %s: %w", path, err)
+	}
+	for _, entry := range file.Entries {
+		s.entries[entry.Id] = entry
+	}
+
+	return s, nil
+}
+
+func (s *Store) Get(id string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[id]
+	return entry, exists
+}
+
+// Set records status for id and saves the store
+func (s *Store) Set(id string, status Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[id]
+	entry.Id, entry.Status, entry.Updated = id, status, time.Now()
+	s.entries[id] = entry
+	return s.save()
+}
+
+// Import records entries in bulk, saving the store once. An entry already
+// recorded is merged with the imported one: its status only moves forward from
+// watchlist to dismissed to watched, and its rating is only replaced by a newer
+// one and its title is kept when the import has none.
+func (s *Store) Import(entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.Updated.IsZero() {
+			entry.Updated = now
+		}
+		if existing, exists := s.entries[entry.Id]; exists {
+			entry = mergeEntry(existing, entry)
+		}
+		s.entries[entry.Id] = entry
+	}
+	return s.save()
+}
+
+// statusRank orders statuses by how far along a title is
+var statusRank = map[Status]int{Watchlist: 1, Dismissed: 2, Watched: 3}
+
+// mergeEntry combines an imported entry with the one already recorded for it
+func mergeEntry(existing, imported Entry) Entry {
+	merged := existing
+	if statusRank[imported.Status] > statusRank[existing.Status] {
+		merged.Status, merged.Updated = imported.Status, imported.Updated
+	}
+	if imported.Rating > 0 && (existing.Rating == 0 || !imported.Rated.Before(existing.Rated)) {
+		merged.Rating, merged.Rated = imported.Rating, imported.Rated
+	}
+	if imported.Title != "" {
+		merged.Title = imported.Title
+	}
+	if merged.Source == "" {
+		merged.Source = imported.Source
+	}
+	return merged
+}
+
+// Ratings returns the user's own ratings keyed by IMDB id
+func (s *Store) Ratings() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ratings := make(map[string]int)
+	for id, entry := range s.entries {
+		if entry.Rating > 0 {
+			ratings[id] = entry.Rating
+		}
+	}
+	return ratings
+}
+
+// Entries returns every entry with status, oldest first
+func (s *Store) Entries(status Status) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []Entry
+	for _, entry := range s.entries {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if c := a.Updated.Compare(b.Updated); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return entries
+}
+
+// Remove deletes id if it has status, reporting whether it did
+func (s *Store) Remove(id string, status Status) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.entries[id]; !exists || entry.Status != status {
+		return false, nil
+	}
+	delete(s.entries, id)
+	return true, s.save()
+}
+
+// Seen reports whether id has been watched or dismissed
+func (s *Store) Seen(id string) bool {
+	entry, exists := s.Get(id)
+	return exists && (entry.Status == Watched || entry.Status == Dismissed)
+}
+
+func (s *Store) MarkWatched(id string) error {
+	return s.Set(id, Watched)
+}
+
+func (s *Store) MarkDismissed(id string) error {
+	return s.Set(id, Dismissed)
+}
+
+func (s *Store) OnWatchlist(id string) bool {
+	entry, exists := s.Get(id)
+	return exists && entry.Status == Watchlist
+}
+
+// AddToWatchlist saves id to watch later, keeping anything already recorded for
+// it such as an imported rating. Like Import, it leaves titles already watched
+// or dismissed as they are.
+func (s *Store) AddToWatchlist(id, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.entries[id]
+	if exists && (entry.Status == Watched || entry.Status == Dismissed) {
+		return nil
+	}
+	entry.Id, entry.Status, entry.Updated = id, Watchlist, time.Now()
+	if title != "" {
+		entry.Title = title
+	}
+	s.entries[id] = entry
+	return s.save()
+}
+
+// save writes to a temporary file first so a crash never leaves a truncated store
+func (s *Store) save() error {
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	data, err := json.MarshalIndent(storeFile{Entries: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("creating store directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_PersistsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", historyFileName)
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error opening missing store: %v", err)
+	}
+	if err := s.MarkWatched("tt0078748"); err != nil {
+		t.Fatalf("Unexpected error marking watched: %v", err)
+	}
+	if err := s.MarkDismissed("tt0090605"); err != nil {
+		t.Fatalf("Unexpected error marking dismissed: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reopening store: %v", err)
+	}
+
+	if entry, exists := reopened.Get("tt0078748"); !exists || entry.Status != Watched {
+		t.Errorf("Expected tt0078748 to be watched, got %+v", entry)
+	}
+	if entry, exists := reopened.Get("tt0090605"); !exists || entry.Status != Dismissed {
+		t.Errorf("Expected tt0090605 to be dismissed, got %+v", entry)
+	}
+	if !reopened.Seen("tt0078748") || !reopened.Seen("tt0090605") || reopened.Seen("tt0103644") {
+		t.Error("Seen should only report watched or dismissed titles")
+	}
+}
+
+func TestStore_Watchlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFileName)
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error opening missing store: %v", err)
+	}
+	if err := s.AddToWatchlist("tt0078748", "Alien"); err != nil {
+		t.Fatalf("Unexpected error adding to watchlist: %v", err)
+	}
+	if err := s.AddToWatchlist("tt0090605", "Aliens"); err != nil {
+		t.Fatalf("Unexpected error adding to watchlist: %v", err)
+	}
+	if err := s.MarkWatched("tt0090605"); err != nil {
+		t.Fatalf("Unexpected error marking watched: %v", err)
+	}
+
+	if !s.OnWatchlist("tt0078748") || s.OnWatchlist("tt0090605") {
+		t.Error("Watching a title should take it off the watchlist")
+	}
+	if s.Seen("tt0078748") {
+		t.Error("Titles on the watchlist should not count as seen")
+	}
+	if entry, _ := s.Get("tt0090605"); entry.Title != "Aliens" {
+		t.Errorf("Expected marking watched to keep the title, got %q", entry.Title)
+	}
+
+	if removed, err := s.Remove("tt0090605", Watchlist); err != nil || removed {
+		t.Errorf("Remove should not delete a watched title, got removed=%v err=%v", removed, err)
+	}
+	if removed, err := s.Remove("tt0078748", Watchlist); err != nil || !removed {
+		t.Errorf("Expected tt0078748 to be removed, got removed=%v err=%v", removed, err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reopening store: %v", err)
+	}
+	if entries := reopened.Entries(Watchlist); len(entries) != 0 {
+		t.Errorf("Expected an empty watchlist, got %+v", entries)
+	}
+	if entries := reopened.Entries(Watched); len(entries) != 1 || entries[0].Id != "tt0090605" {
+		t.Errorf("Expected tt0090605 to be the only watched title, got %+v", entries)
+	}
+}
+
+func TestStore_AddToWatchlistKeepsEntry(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), historyFileName))
+	if err != nil {
+		t.Fatalf("Unexpected error opening missing store: %v", err)
+	}
+	rated := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.Import([]Entry{
+		{Id: "tt0078748", Title: "Alien", Status: Watched, Rating: 9, Rated: rated, Source: "imdb"},
+		{Id: "tt0090605", Title: "Aliens", Status: Watchlist, Rating: 8, Rated: rated, Source: "letterboxd"},
+		{Id: "tt0103644", Title: "Alien 3", Status: Dismissed},
+	}); err != nil {
+		t.Fatalf("Unexpected error importing: %v", err)
+	}
+
+	for _, id := range []string{"tt0078748", "tt0090605", "tt0103644"} {
+		if err := s.AddToWatchlist(id, ""); err != nil {
+			t.Fatalf("Unexpected error adding to watchlist: %v", err)
+		}
+	}
+
+	if entry, _ := s.Get("tt0078748"); entry.Status != Watched || entry.Rating != 9 {
+		t.Errorf("Expected a watched title to stay watched with its rating, got %+v", entry)
+	}
+	if entry, _ := s.Get("tt0103644"); entry.Status != Dismissed {
+		t.Errorf("Expected a dismissed title to stay dismissed, got %+v", entry)
+	}
+	if entry, _ := s.Get("tt0090605"); entry.Title != "Aliens" || entry.Rating != 8 || !entry.Rated.Equal(rated) || entry.Source != "letterboxd" {
+		t.Errorf("Expected the imported details to be kept, got %+v", entry)
+	}
+}
+
+func TestStore_ImportMergesEntries(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), historyFileName))
+	if err != nil {
+		t.Fatalf("Unexpected error opening missing store: %v", err)
+	}
+	older := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// An IMDB ratings export, then a Letterboxd export of the same titles
+	if err := s.Import([]Entry{
+		{Id: "tt0078748", Title: "Alien", Status: Watched, Rating: 9, Rated: newer, Source: "imdb"},
+		{Id: "tt0090605", Title: "Aliens", Status: Watched, Rating: 7, Rated: older, Source: "imdb"},
+		{Id: "tt0103644", Title: "Alien 3", Status: Watched, Rating: 5, Rated: older, Source: "imdb"},
+	}); err != nil {
+		t.Fatalf("Unexpected error importing from IMDB: %v", err)
+	}
+	if err := s.Import([]Entry{
+		{Id: "tt0078748", Status: Watched, Source: "letterboxd"},
+		{Id: "tt0090605", Title: "Aliens", Status: Watched, Rating: 8, Rated: newer, Source: "letterboxd"},
+		{Id: "tt0103644", Title: "Alien³", Status: Watchlist, Rating: 4, Rated: older.AddDate(0, 0, -1), Source: "letterboxd"},
+		{Id: "tt0118583", Title: "Alien Resurrection", Status: Watchlist, Source: "letterboxd"},
+	}); err != nil {
+		t.Fatalf("Unexpected error importing from Letterboxd: %v", err)
+	}
+
+	if entry, _ := s.Get("tt0078748"); entry.Status != Watched || entry.Title != "Alien" || entry.Rating != 9 || !entry.Rated.Equal(newer) {
+		t.Errorf("Expected an import without a rating or title to keep them, got %+v", entry)
+	}
+	if entry, _ := s.Get("tt0090605"); entry.Rating != 8 || !entry.Rated.Equal(newer) {
+		t.Errorf("Expected a newer rating to replace the older one, got %+v", entry)
+	}
+	if entry, _ := s.Get("tt0103644"); entry.Status != Watched || entry.Rating != 5 || !entry.Rated.Equal(older) || entry.Title != "Alien³" {
+		t.Errorf("Expected the status and rating not to go back, got %+v", entry)
+	}
+	if entry, _ := s.Get("tt0118583"); entry.Status != Watchlist {
+		t.Errorf("Expected a new title to be added, got %+v", entry)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	added := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Id: "tt0078748", Title: "Alien", Status: Watchlist, Updated: added},
+		{Id: "tt0000001", Title: `Say "Hi", Again`, Status: Watchlist, Updated: added},
+	}
+
+	var out strings.Builder
+	if err := WriteCSV(&out, entries, "https://www.imdb.com/title"); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v", err)
+	}
+
+	expected := "Const,Title,Added,URL\n" +
+		"tt0078748,Alien,2024-03-01,https://www.imdb.com/title/tt0078748/\n" +
+		"tt0000001,\"Say \"\"Hi\"\", Again\",2024-03-01,https://www.imdb.com/title/tt0000001/\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,107 @@
+// Package term puts a terminal into raw mode and decodes key presses for the
+// full-screen interface, using only the standard library and stty
+package term
+
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+)
+
+// Key is a single key press, Rune is only set for KeyRune
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+var escapeSequences = map[string]KeyCode{
+	"[A":  KeyUp,
+	"[B":  KeyDown,
+	"[C":  KeyRight,
+	"[D":  KeyLeft,
+	"[H":  KeyHome,
+	"[F":  KeyEnd,
+	"OA":  KeyUp,
+	"OB":  KeyDown,
+	"OC":  KeyRight,
+	"OD":  KeyLeft,
+	"OH":  KeyHome,
+	"OF":  KeyEnd,
+	"[1~": KeyHome,
+	"[4~": KeyEnd,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+}
+
+// ParseKeys decodes the bytes of one read from a raw terminal. An escape byte
+// which does not start a known sequence is reported as KeyEscape, and unknown
+// sequences are dropped.
+func ParseKeys(input []byte) []Key {
+	var keys []Key
+	runes := []rune(string(input))
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\r', '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case 127, '\b':
+			keys = append(keys, Key{Code: KeyBackspace})
+		case 3:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case 27:
+			length, code := parseEscape(runes[i+1:])
+			if length == 0 {
+				keys = append(keys, Key{Code: KeyEscape})
+				continue
+			}
+			if code != KeyEscape {
+				keys = append(keys, Key{Code: code})
+			}
+			i += length
+		default:
+			if r >= ' ' {
+				keys = append(keys, Key{Code: KeyRune, Rune: r})
+			}
+		}
+	}
+
+	return keys
+}
+
+// parseEscape returns how many runes after an escape byte form a sequence, and
+// KeyEscape for a well-formed sequence which is not recognised
+func parseEscape(runes []rune) (int, KeyCode) {
+	if len(runes) < 2 || (runes[0] != '[' && runes[0] != 'O') {
+		return 0, KeyEscape
+	}
+
+	// A sequence ends at the first letter or ~ after its parameters
+	for end := 1; end < len(runes); end++ {
+		r := runes[end]
+		if r == '~' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
+			if code, known := escapeSequences[string(runes[:end+1])]; known {
+				return end + 1, code
+			}
+			return end + 1, KeyEscape
+		}
+		if (r < '0' || r > '9') && r != ';' {
+			break
+		}
+	}
+	return 0, KeyEscape
+}
@@ -0,0 +1,31 @@
+package term
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Key
+	}{
+		{"runes", "aé", []Key{{Code: KeyRune, Rune: 'a'}, {Code: KeyRune, Rune: 'é'}}},
+		{"control keys", "\r\t\x7f\x03", []Key{{Code: KeyEnter}, {Code: KeyTab}, {Code: KeyBackspace}, {Code: KeyCtrlC}}},
+		{"arrows", "\x1b[A\x1b[B\x1bOC\x1b[D", []Key{{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}}},
+		{"paging", "\x1b[5~\x1b[6~\x1b[H\x1b[4~", []Key{{Code: KeyPageUp}, {Code: KeyPageDown}, {Code: KeyHome}, {Code: KeyEnd}}},
+		{"lone escape", "\x1b", []Key{{Code: KeyEscape}}},
+		{"escape then rune", "\x1bq", []Key{{Code: KeyEscape}, {Code: KeyRune, Rune: 'q'}}},
+		{"unknown sequence dropped", "\x1b[15~x", []Key{{Code: KeyRune, Rune: 'x'}}},
+		{"modified arrow dropped", "\x1b[1;5A", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keys := ParseKeys([]byte(tt.input)); !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package term
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("full-screen mode is not supported on this platform")
+
+func MakeRaw(f *os.File) (func() error, error) {
+	return nil, errUnsupported
+}
+
+func Size(f *os.File) (int, int, error) {
+	return 0, 0, errUnsupported
+}
+
+func NotifyResize(c chan<- os.Signal) {}
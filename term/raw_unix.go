@@ -0,0 +1,55 @@
+//go:build unix
+
+package term
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// MakeRaw switches the terminal on f to raw mode, returning a function which
+// restores the previous settings
+func MakeRaw(f *os.File) (func() error, error) {
+	saved, err := stty(f, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("reading terminal settings: %w", err)
+	}
+	if _, err := stty(f, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+
+	return func() error {
+		_, err := stty(f, strings.TrimSpace(saved))
+		return err
+	}, nil
+}
+
+// Size returns the width and height of the terminal on f
+func Size(f *os.File) (int, int, error) {
+	out, err := stty(f, "size")
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading terminal size: %w", err)
+	}
+
+	var height, width int
+	if _, err := fmt.Sscan(out, &height, &width); err != nil {
+		return 0, 0, fmt.Errorf("parsing terminal size %q: %w", out, err)
+	}
+	return width, height, nil
+}
+
+// NotifyResize sends to c whenever the terminal is resized
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}